@@ -0,0 +1,767 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/cache"
+	"github.com/grokipedia/cli/internal/formatter"
+	"github.com/grokipedia/cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheListFormat   string
+	cacheListOutput   outputFlags
+	cacheListNoHeader bool
+
+	cacheShowFormat   string
+	cacheShowOutput   outputFlags
+	cacheShowNoHeader bool
+
+	cacheStatsFormat   string
+	cacheStatsOutput   outputFlags
+	cacheStatsNoHeader bool
+
+	cachePurgeOlderThan time.Duration
+	cachePurgeEndpoint  string
+	cachePurgeSlug      string
+
+	cacheWarmFile    string
+	cacheWarmSearch  string
+	cacheWarmLimit   int
+	cacheWarmWorkers int
+)
+
+// cacheCmd groups the cache management commands
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and manage the response cache",
+	Long: `Inspect and manage cached API responses.
+
+Entries are stored in the cache directory whether or not --no-cache is set,
+so these commands always operate on the configured cache directory.`,
+}
+
+// cacheListCmd lists cached entries
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached entries",
+	Long:  `List cached entries with their endpoint, parameters, age, size and expiry.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := formatter.ValidateFormat(cacheListFormat, formatter.TabularFormats); err != nil {
+			return &api.InvalidArgsError{Message: err.Error()}
+		}
+
+		store, err := cacheStore()
+		if err != nil {
+			return err
+		}
+
+		infos, err := store.Entries()
+		if err != nil {
+			return fmt.Errorf("failed to read cache: %w", err)
+		}
+
+		return outputCacheEntries(infos, cacheListFormat)
+	},
+}
+
+// cacheShowCmd prints a cached response
+var cacheShowCmd = &cobra.Command{
+	Use:   "show <key|slug>",
+	Short: "Show a cached response",
+	Long: `Print the cached response for a cache key, or for every cached page
+request with the given slug. The raw format prints each response as stored,
+after a comment line with its request and one with its timestamps; the other
+formats write the entries with their metadata and response as data.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := formatter.ValidateFormat(cacheShowFormat, cacheShowFormats); err != nil {
+			return &api.InvalidArgsError{Message: err.Error()}
+		}
+		if cacheShowFormat == "raw" && (cacheShowOutput.fields != "" || cacheShowOutput.query != "") {
+			return &api.InvalidArgsError{Message: "--fields and --query do not apply to raw output"}
+		}
+
+		store, err := cacheStore()
+		if err != nil {
+			return err
+		}
+
+		infos, err := store.Entries()
+		if err != nil {
+			return fmt.Errorf("failed to read cache: %w", err)
+		}
+
+		matched := matchCacheEntries(infos, args[0])
+		if len(matched) == 0 {
+			return &api.NotFoundError{Resource: fmt.Sprintf("cache entry %q", args[0])}
+		}
+
+		responses := make(cachedResponses, 0, len(matched))
+		for _, info := range matched {
+			data, err := store.Data(info.Key)
+			if err != nil {
+				return err
+			}
+			responses = append(responses, newCachedResponse(info, data))
+		}
+		return outputCachedResponses(responses, cacheShowFormat)
+	},
+}
+
+// cachePurgeCmd deletes entries matching filters
+var cachePurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete cached entries matching filters",
+	Long: `Delete cached entries older than a duration, for an endpoint or for a
+page slug. Filters combine: an entry is deleted only if it matches all of them.`,
+	Example: `  grokipedia cache purge --older-than 24h
+  grokipedia cache purge --endpoint search
+  grokipedia cache purge --slug Python_programming_language`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := cachePurgeFilter(cachePurgeOlderThan, cachePurgeEndpoint, cachePurgeSlug)
+		if err != nil {
+			return err
+		}
+
+		store, err := cacheStore()
+		if err != nil {
+			return err
+		}
+
+		// Entries deleted before a failure are still reported
+		purged, err := store.Purge(filter)
+		if purged > 0 || err == nil {
+			fmt.Printf("Purged %d cache %s.\n", purged, plural(purged, "entry", "entries"))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to purge cache: %w", err)
+		}
+		return nil
+	},
+}
+
+// cacheClearCmd deletes every entry
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached entries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := cacheStore()
+		if err != nil {
+			return err
+		}
+
+		if err := store.Clear(); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+
+		fmt.Println("Cache cleared.")
+		return nil
+	},
+}
+
+// cacheStatsCmd summarizes cache usage
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache hit/miss counts and size",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := formatter.ValidateFormat(cacheStatsFormat, cacheStatsFormats); err != nil {
+			return &api.InvalidArgsError{Message: err.Error()}
+		}
+
+		store, err := cacheStore()
+		if err != nil {
+			return err
+		}
+
+		stats, err := store.Stats()
+		if err != nil {
+			return fmt.Errorf("failed to read cache: %w", err)
+		}
+
+		return outputCacheStats(stats, cacheStatsFormat)
+	},
+}
+
+// cacheWarmCmd prefetches pages into the cache
+var cacheWarmCmd = &cobra.Command{
+	Use:   "warm [slug...]",
+	Short: "Prefetch pages into the cache",
+	Long: `Fetch pages into the cache, with and without content, so that they can
+later be read with --offline. Slugs are taken from the arguments, from a
+file with one slug per line (--file, or --file - for stdin), and from the
+results of a search (--search). Progress is written to stderr and pages that
+could not be fetched are listed at the end.`,
+	Example: `  grokipedia cache warm Python_programming_language Go_(programming_language)
+  grokipedia cache warm --file slugs.txt --workers 8
+  grokipedia cache warm --search "machine learning" --limit 50`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cacheWarmWorkers < 1 {
+			return &api.InvalidArgsError{Message: "--workers must be at least 1"}
+		}
+
+		store, err := cacheStore()
+		if err != nil {
+			return err
+		}
+		if store.IsOffline() {
+			return &api.InvalidArgsError{Message: "cache warm cannot run with --offline"}
+		}
+
+		slugs, err := cacheWarmSlugs(cmd.Context(), store, args, cacheWarmFile, cacheWarmSearch, cacheWarmLimit)
+		if err != nil {
+			return err
+		}
+		if len(slugs) == 0 {
+			return &api.InvalidArgsError{Message: "warm requires slugs as arguments, --file or --search"}
+		}
+
+		failures := cache.WarmPages(cmd.Context(), store, getClient(), slugs, cache.WarmOptions{
+			Workers:  cacheWarmWorkers,
+			Progress: os.Stderr,
+		})
+		return warmSummary(len(slugs), failures)
+	},
+}
+
+// cacheExportCmd writes the cache to a bundle
+var cacheExportCmd = &cobra.Command{
+	Use:   "export <file.tar.gz>",
+	Short: "Export cached entries to a bundle",
+	Long: `Write every cached entry, with the request, API base URL and timestamps
+it was stored with, to a gzip-compressed tar bundle that 'cache import' can
+load. Use - to write the bundle to stdout.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := cacheStore()
+		if err != nil {
+			return err
+		}
+
+		if args[0] == "-" {
+			_, err := store.Export(os.Stdout)
+			return err
+		}
+
+		f, err := os.Create(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to create bundle: %w", err)
+		}
+		n, err := store.Export(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(args[0])
+			return fmt.Errorf("failed to export cache: %w", err)
+		}
+
+		fmt.Printf("Exported %d cache %s to %s.\n", n, plural(n, "entry", "entries"), args[0])
+		return nil
+	},
+}
+
+// cacheImportCmd merges a bundle into the cache
+var cacheImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import cached entries from a bundle",
+	Long: `Merge the entries of a bundle written by 'cache export' into the cache.
+When both hold an entry for the same request, the one stored most recently is
+kept. Use - to read the bundle from stdin.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := cacheStore()
+		if err != nil {
+			return err
+		}
+
+		r := io.Reader(os.Stdin)
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return &api.InvalidArgsError{Message: fmt.Sprintf("failed to open bundle: %v", err)}
+			}
+			defer func() { _ = f.Close() }()
+			r = f
+		}
+
+		result, err := store.Import(r)
+		if err != nil {
+			return fmt.Errorf("failed to import cache: %w", err)
+		}
+
+		fmt.Printf("Imported %d cache %s; kept %d newer local %s.\n",
+			result.Imported, plural(result.Imported, "entry", "entries"),
+			result.Kept, plural(result.Kept, "entry", "entries"))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheListCmd, cacheShowCmd, cachePurgeCmd, cacheClearCmd, cacheStatsCmd, cacheWarmCmd,
+		cacheExportCmd, cacheImportCmd)
+
+	cacheListCmd.Flags().StringVar(&cacheListFormat, "format", "table", "Output format: table, json, ndjson, yaml, markdown, plain, list, csv, tsv, template")
+	cacheListOutput.addTemplateFlags(cacheListCmd)
+	cacheListOutput.addSelectionFlags(cacheListCmd)
+	cacheListCmd.Flags().BoolVar(&cacheListNoHeader, "no-header", false, "Omit the header row in csv and tsv output")
+
+	cacheShowCmd.Flags().StringVar(&cacheShowFormat, "format", "raw", "Output format: raw, table, json, ndjson, yaml, markdown, plain, list, csv, tsv, template")
+	cacheShowOutput.addTemplateFlags(cacheShowCmd)
+	cacheShowOutput.addSelectionFlags(cacheShowCmd)
+	cacheShowCmd.Flags().BoolVar(&cacheShowNoHeader, "no-header", false, "Omit the header row in csv and tsv output")
+
+	cacheStatsCmd.Flags().StringVar(&cacheStatsFormat, "format", "table", "Output format: table, json, ndjson, yaml, markdown, plain, csv, tsv, template")
+	cacheStatsOutput.addTemplateFlags(cacheStatsCmd)
+	cacheStatsOutput.addSelectionFlags(cacheStatsCmd)
+	cacheStatsCmd.Flags().BoolVar(&cacheStatsNoHeader, "no-header", false, "Omit the header row in csv and tsv output")
+
+	cachePurgeCmd.Flags().DurationVar(&cachePurgeOlderThan, "older-than", 0, "Delete entries stored longer ago than this duration (e.g. 24h)")
+	cachePurgeCmd.Flags().StringVar(&cachePurgeEndpoint, "endpoint", "", "Delete entries for an endpoint: search, page, typeahead, constants, edits, edits-by-slug")
+	cachePurgeCmd.Flags().StringVar(&cachePurgeSlug, "slug", "", "Delete entries for a page slug")
+	_ = cacheListCmd.RegisterFlagCompletionFunc("format", completeFormats(formatter.TabularFormats))
+	_ = cacheShowCmd.RegisterFlagCompletionFunc("format", completeFormats(cacheShowFormats))
+	_ = cacheStatsCmd.RegisterFlagCompletionFunc("format", completeFormats(cacheStatsFormats))
+	_ = cachePurgeCmd.RegisterFlagCompletionFunc("endpoint", cobra.FixedCompletions(endpointNames(), cobra.ShellCompDirectiveNoFileComp))
+
+	cacheWarmCmd.Flags().StringVar(&cacheWarmFile, "file", "", "Read slugs from a file, one per line (- for stdin)")
+	cacheWarmCmd.Flags().StringVar(&cacheWarmSearch, "search", "", "Warm the pages found by this search query")
+	cacheWarmCmd.Flags().IntVar(&cacheWarmLimit, "limit", 12, "Number of search results to warm with --search")
+	cacheWarmCmd.Flags().IntVar(&cacheWarmWorkers, "workers", cache.DefaultWarmWorkers, "Number of pages to fetch at once")
+}
+
+// endpointNames returns the names --endpoint accepts, sorted
+func endpointNames() []string {
+	names := make([]string, 0, len(api.EndpointNames))
+	for name := range api.EndpointNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cacheStore returns the cache to inspect. Unlike getCache it is available
+// when caching is disabled so that existing entries can still be managed.
+func cacheStore() (*cache.Cache, error) {
+	if appCache != nil {
+		return appCache, nil
+	}
+	if storeCache == nil {
+		c, err := newCache(getConfig())
+		if err != nil {
+			return nil, err
+		}
+		storeCache = c
+	}
+	return storeCache, nil
+}
+
+// matchCacheEntries returns the entries whose key is arg or whose request
+// has a slug parameter equal to arg
+func matchCacheEntries(infos []cache.Info, arg string) []cache.Info {
+	var matched []cache.Info
+	for _, info := range infos {
+		if info.Key == arg || info.Params().Get("slug") == arg {
+			matched = append(matched, info)
+		}
+	}
+	return matched
+}
+
+// cachePurgeFilter builds the filter for cache purge from its flags. At
+// least one filter is required so that purge never clears the whole cache
+// by accident.
+func cachePurgeFilter(olderThan time.Duration, endpoint, slug string) (cache.Filter, error) {
+	if olderThan < 0 {
+		return cache.Filter{}, &api.InvalidArgsError{Message: "--older-than must not be negative"}
+	}
+
+	f := cache.Filter{OlderThan: olderThan, Slug: slug}
+	if endpoint != "" {
+		path, ok := api.EndpointPath(endpoint)
+		if !ok {
+			return f, &api.InvalidArgsError{Message: fmt.Sprintf("unknown endpoint '%s'", endpoint)}
+		}
+		f.Endpoint = path
+	}
+
+	if f.IsZero() {
+		return f, &api.InvalidArgsError{Message: "purge requires --older-than, --endpoint or --slug (use 'cache clear' to delete everything)"}
+	}
+	return f, nil
+}
+
+// cacheWarmSlugs collects the slugs to warm from the arguments, the slug
+// file and the search results, in that order and without duplicates. The
+// search is the same one the search command runs.
+func cacheWarmSlugs(ctx context.Context, store *cache.Cache, args []string, file, query string, limit int) ([]string, error) {
+	slugs := append([]string(nil), args...)
+
+	if file != "" {
+		r := io.Reader(os.Stdin)
+		if file != "-" {
+			f, err := os.Open(file)
+			if err != nil {
+				return nil, &api.InvalidArgsError{Message: fmt.Sprintf("failed to open slug file: %v", err)}
+			}
+			defer func() { _ = f.Close() }()
+			r = f
+		}
+
+		fromFile, err := readSlugs(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read slugs: %w", err)
+		}
+		slugs = append(slugs, fromFile...)
+	}
+
+	if query != "" {
+		svc := service.New(getClient(), store)
+		results, err := svc.Search(ctx, service.SearchOptions{Query: query, Limit: limit})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results.Results {
+			slugs = append(slugs, r.Slug)
+		}
+	}
+
+	return dedupe(slugs), nil
+}
+
+// readSlugs reads one slug per line, skipping blank lines and lines
+// starting with #
+func readSlugs(r io.Reader) ([]string, error) {
+	var slugs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		slugs = append(slugs, line)
+	}
+	return slugs, scanner.Err()
+}
+
+// dedupe returns values without repeats, keeping the first occurrence
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// warmSummary reports the outcome of cache warm on stderr and returns an
+// error if any page failed
+func warmSummary(total int, failures []cache.WarmFailure) error {
+	fmt.Fprintf(os.Stderr, "Warmed %d of %d %s.\n", total-len(failures), total, plural(total, "page", "pages"))
+	if len(failures) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, "Failed:")
+	for _, f := range failures {
+		fmt.Fprintf(os.Stderr, "  %s: %v\n", f.Slug, f.Err)
+	}
+	return fmt.Errorf("failed to warm %d %s", len(failures), plural(len(failures), "page", "pages"))
+}
+
+// cacheShowFormats are the formats of cache show: raw, which prints the
+// responses as stored, and the formats of the other commands
+var cacheShowFormats = append([]string{"raw"}, formatter.TabularFormats...)
+
+// cacheStatsFormats are the formats of cache stats, which has no list of
+// items for the list format
+var cacheStatsFormats = slices.DeleteFunc(slices.Clone(formatter.TabularFormats), func(f string) bool {
+	return f == string(formatter.FormatList)
+})
+
+// maxCacheCellWidth is the width at which long cells, such as parameter
+// lists, are truncated in cache tables
+const maxCacheCellWidth = 60
+
+// cacheEntries is the output of cache list
+type cacheEntries []cache.Info
+
+var (
+	_ formatter.Renderable = cacheEntries(nil)
+	_ formatter.Itemized   = cacheEntries(nil)
+	_ formatter.Renderable = cachedResponses(nil)
+	_ formatter.Itemized   = cachedResponses(nil)
+	_ formatter.Renderable = cacheStats{}
+)
+
+// Table implements formatter.Renderable
+func (e cacheEntries) Table() formatter.Table {
+	now := time.Now()
+	t := formatter.Table{
+		Headers:  []string{"Key", "Endpoint", "Params", "Age", "Size", "Expires"},
+		Empty:    "Cache is empty.",
+		MaxWidth: maxCacheCellWidth,
+	}
+	for _, info := range e {
+		endpoint, params := cacheRequest(info)
+		t.Rows = append(t.Rows, []string{info.Key, endpoint, params,
+			formatter.FormatAge(now.Sub(info.CreatedTime())),
+			formatter.FormatBytes(info.Size),
+			formatter.FormatExpiry(info.ExpiresAt(), now)})
+	}
+	return t
+}
+
+// Document implements formatter.Renderable
+func (e cacheEntries) Document() formatter.Document {
+	now := time.Now()
+	doc := formatter.Document{Heading: "Cache Entries", Empty: "Cache is empty."}
+	for _, info := range e {
+		endpoint, params := cacheRequest(info)
+		doc.Items = append(doc.Items, formatter.Item{
+			Title: info.Key,
+			Details: []string{
+				fmt.Sprintf("Endpoint: %s, Params: %s", endpoint, params),
+				fmt.Sprintf("Age: %s, Size: %s, Expires: %s", formatter.FormatAge(now.Sub(info.CreatedTime())),
+					formatter.FormatBytes(info.Size), formatter.FormatExpiry(info.ExpiresAt(), now)),
+			},
+		})
+	}
+	return doc
+}
+
+// List implements formatter.Renderable, listing the keys
+func (e cacheEntries) List() []string {
+	keys := make([]string, 0, len(e))
+	for _, info := range e {
+		keys = append(keys, info.Key)
+	}
+	return keys
+}
+
+// Items implements formatter.Itemized
+func (e cacheEntries) Items() []interface{} {
+	items := make([]interface{}, len(e))
+	for i, info := range e {
+		items[i] = info
+	}
+	return items
+}
+
+// cachedResponse is an entry printed by cache show, with the response it
+// holds
+type cachedResponse struct {
+	cache.Info
+	// Data is the stored response, or a JSON string holding it if it is
+	// not JSON
+	Data json.RawMessage `json:"data"`
+
+	// stored is the response as stored, for the raw format
+	stored []byte
+}
+
+// newCachedResponse returns the entry described by info holding data
+func newCachedResponse(info cache.Info, data []byte) cachedResponse {
+	resp := cachedResponse{Info: info, Data: data, stored: data}
+	if !json.Valid(data) {
+		resp.Data, _ = json.Marshal(string(data))
+	}
+	return resp
+}
+
+// cachedResponses is the output of cache show
+type cachedResponses []cachedResponse
+
+// Table implements formatter.Renderable
+func (r cachedResponses) Table() formatter.Table {
+	t := formatter.Table{
+		Headers:  []string{"Key", "Endpoint", "Params", "Stored", "Expires"},
+		MaxWidth: maxCacheCellWidth,
+	}
+	for _, resp := range r {
+		endpoint, params := cacheRequest(resp.Info)
+		t.Rows = append(t.Rows, []string{resp.Key, endpoint, params,
+			resp.CreatedTime().Format(formatter.DateLayout),
+			resp.ExpiresAt().Format(formatter.DateLayout)})
+	}
+	return t
+}
+
+// Document implements formatter.Renderable, with an item per entry whose
+// last detail is the indented response
+func (r cachedResponses) Document() formatter.Document {
+	doc := formatter.Document{Heading: "Cached Responses"}
+	for _, resp := range r {
+		var data bytes.Buffer
+		// Continuation lines line up with the detail's indentation
+		if err := json.Indent(&data, resp.Data, "  ", "  "); err != nil {
+			data.Reset()
+			data.Write(resp.Data)
+		}
+		doc.Items = append(doc.Items, formatter.Item{
+			Title: resp.Key,
+			Details: []string{
+				cacheRequestLine(resp.Info),
+				fmt.Sprintf("Stored: %s, Expires: %s", resp.CreatedTime().Format(formatter.DateLayout),
+					resp.ExpiresAt().Format(formatter.DateLayout)),
+				data.String(),
+			},
+		})
+	}
+	return doc
+}
+
+// List implements formatter.Renderable, listing the keys
+func (r cachedResponses) List() []string {
+	keys := make([]string, 0, len(r))
+	for _, resp := range r {
+		keys = append(keys, resp.Key)
+	}
+	return keys
+}
+
+// Items implements formatter.Itemized
+func (r cachedResponses) Items() []interface{} {
+	items := make([]interface{}, len(r))
+	for i, resp := range r {
+		items[i] = resp
+	}
+	return items
+}
+
+// cacheStats is the output of cache stats
+type cacheStats cache.Stats
+
+// Table implements formatter.Renderable
+func (s cacheStats) Table() formatter.Table {
+	t := formatter.Table{Headers: []string{"Stat", "Value"}}
+	for _, f := range s.Document().Fields {
+		t.Rows = append(t.Rows, []string{f.Name, f.Value})
+	}
+	return t
+}
+
+// Document implements formatter.Renderable
+func (s cacheStats) Document() formatter.Document {
+	return formatter.Document{
+		Heading: "Cache Statistics",
+		Fields: []formatter.Field{
+			{Name: "Entries", Value: strconv.Itoa(s.Entries)},
+			{Name: "Expired", Value: strconv.Itoa(s.Expired)},
+			{Name: "Total size", Value: formatter.FormatBytes(s.TotalSize)},
+			{Name: "Hits", Value: strconv.FormatInt(s.Hits, 10)},
+			{Name: "Misses", Value: strconv.FormatInt(s.Misses, 10)},
+			{Name: "Hit ratio", Value: fmt.Sprintf("%.1f%%", cache.Stats(s).HitRatio()*100)},
+		},
+	}
+}
+
+// List implements formatter.Renderable. Statistics are not a list, so
+// cache stats does not offer the list format.
+func (s cacheStats) List() []string {
+	return nil
+}
+
+// cacheRequest returns the endpoint name and query parameters of the
+// request an entry answers, or dashes if it was stored without one
+func cacheRequest(info cache.Info) (endpoint, params string) {
+	if info.Request == "" {
+		return "-", "-"
+	}
+	return api.EndpointName(info.Endpoint()), info.Params().Encode()
+}
+
+// cacheRequestLine returns the request an entry answers, for display
+func cacheRequestLine(info cache.Info) string {
+	if info.Request == "" {
+		return "(unknown request)"
+	}
+	return info.Request
+}
+
+// outputCacheEntries outputs cache entries in the specified format
+func outputCacheEntries(infos []cache.Info, format string) error {
+	opts, err := cacheListOutput.options(format, cacheListNoHeader)
+	if err != nil {
+		return err
+	}
+	if infos == nil {
+		infos = []cache.Info{}
+	}
+	return service.Write(os.Stdout, cacheEntries(infos), format, opts)
+}
+
+// outputCachedResponses outputs the entries printed by cache show in the
+// specified format
+func outputCachedResponses(responses cachedResponses, format string) error {
+	if format == "raw" {
+		for _, resp := range responses {
+			if err := outputCachedResponseRaw(resp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	opts, err := cacheShowOutput.options(format, cacheShowNoHeader)
+	if err != nil {
+		return err
+	}
+	return service.Write(os.Stdout, responses, format, opts)
+}
+
+// outputCachedResponseRaw prints an entry's request followed by its stored
+// data
+func outputCachedResponseRaw(resp cachedResponse) error {
+	fmt.Printf("# %s %s\n", resp.Key, cacheRequestLine(resp.Info))
+	fmt.Printf("# stored %s, expires %s\n",
+		resp.CreatedTime().Format(time.RFC3339), resp.ExpiresAt().Format(time.RFC3339))
+
+	// Indenting the stored bytes keeps their key order and numbers
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, resp.stored, "", "  "); err != nil {
+		// Not JSON; print as stored
+		fmt.Println(string(resp.stored))
+		return nil
+	}
+	fmt.Println(buf.String())
+	return nil
+}
+
+// outputCacheStats outputs cache statistics in the specified format
+func outputCacheStats(stats cache.Stats, format string) error {
+	opts, err := cacheStatsOutput.options(format, cacheStatsNoHeader)
+	if err != nil {
+		return err
+	}
+	return service.Write(os.Stdout, cacheStats(stats), format, opts)
+}
+
+// plural returns singular when n is 1 and pluralForm otherwise
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/apitest"
+	"github.com/grokipedia/cli/internal/cache"
+)
+
+// withCache installs a cache in a temporary directory holding one page and
+// one search entry
+func withCache(t *testing.T) *cache.Cache {
+	t.Helper()
+
+	c := cache.New(t.TempDir(), 3600)
+	old := appCache
+	appCache = c
+	t.Cleanup(func() { appCache = old })
+
+	page := cache.Request{Endpoint: api.EndpointPage, Params: map[string]interface{}{"slug": "Go"}}
+	search := cache.Request{Endpoint: api.EndpointSearch, Params: map[string]interface{}{"q": "go"}}
+	old24h := time.Now().Add(-24 * time.Hour).Unix()
+
+	if err := c.SetEntry(c.Key(page), []byte(`{"found":true}`), cache.CacheMetadata{Request: page.Canonical()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetEntry(c.Key(search), []byte(`{"results":[]}`), cache.CacheMetadata{Request: search.Canonical(), CreatedAt: old24h}); err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestCacheListCommand(t *testing.T) {
+	withCache(t)
+
+	oldFormat := cacheListFormat
+	t.Cleanup(func() { cacheListFormat = oldFormat })
+	cacheListFormat = "json"
+
+	output, err := runCommand(t, cacheListCmd)
+	if err != nil {
+		t.Fatalf("cache list error = %v", err)
+	}
+
+	var infos []cache.Info
+	if err := json.Unmarshal([]byte(output), &infos); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(infos))
+	}
+	if infos[0].Endpoint() != api.EndpointSearch {
+		t.Errorf("Expected oldest entry to be the search, got %q", infos[0].Request)
+	}
+}
+
+func TestCacheListCommandTable(t *testing.T) {
+	withCache(t)
+
+	oldFormat := cacheListFormat
+	t.Cleanup(func() { cacheListFormat = oldFormat })
+	cacheListFormat = "table"
+
+	output, err := runCommand(t, cacheListCmd)
+	if err != nil {
+		t.Fatalf("cache list error = %v", err)
+	}
+
+	for _, want := range []string{"page", "slug=Go", "search", "q=go", "24h"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output, got %q", want, output)
+		}
+	}
+}
+
+func TestCacheListCommandFields(t *testing.T) {
+	withCache(t)
+
+	oldFormat, oldOutput := cacheListFormat, cacheListOutput
+	t.Cleanup(func() { cacheListFormat, cacheListOutput = oldFormat, oldOutput })
+	cacheListFormat, cacheListOutput.fields = "csv", "request"
+
+	output, err := runCommand(t, cacheListCmd)
+	if err != nil {
+		t.Fatalf("cache list error = %v", err)
+	}
+	if want := "request\n/api/full-text-search?q=go\n/api/page?slug=Go\n"; output != want {
+		t.Errorf("Expected %q, got %q", want, output)
+	}
+}
+
+func TestCacheShowCommand(t *testing.T) {
+	withCache(t)
+
+	output, err := runCommand(t, cacheShowCmd, "Go")
+	if err != nil {
+		t.Fatalf("cache show error = %v", err)
+	}
+	if !strings.Contains(output, `"found": true`) {
+		t.Errorf("Expected cached page in output, got %q", output)
+	}
+
+	_, err = runCommand(t, cacheShowCmd, "missing")
+	if api.GetExitCode(err) != api.ExitNotFound {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestOutputCachedResponseRaw(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		want   string
+	}{
+		{"key order and large numbers", `{"z":1,"a":12345678901234567890}`, "{\n  \"z\": 1,\n  \"a\": 12345678901234567890\n}\n"},
+		{"not json", "plain text", "plain text\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newCachedResponse(cache.Info{Key: "key"}, []byte(tt.stored))
+			output := captureOutput(t, func() {
+				if err := outputCachedResponseRaw(resp); err != nil {
+					t.Errorf("outputCachedResponseRaw() error = %v", err)
+				}
+			})
+
+			// Skip the two comment lines describing the entry
+			lines := strings.SplitN(output, "\n", 3)
+			if len(lines) != 3 || lines[2] != tt.want {
+				t.Errorf("Expected the stored data %q, got %q", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestCacheShowCommandQuery(t *testing.T) {
+	withCache(t)
+
+	oldFormat, oldOutput := cacheShowFormat, cacheShowOutput
+	t.Cleanup(func() { cacheShowFormat, cacheShowOutput = oldFormat, oldOutput })
+	cacheShowOutput.query = ".[].data.found"
+
+	if _, err := runCommand(t, cacheShowCmd, "Go"); api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Expected invalid args error for --query with raw output, got %v", err)
+	}
+
+	cacheShowFormat = "json"
+	output, err := runCommand(t, cacheShowCmd, "Go")
+	if err != nil {
+		t.Fatalf("cache show error = %v", err)
+	}
+	if output != "true\n" {
+		t.Errorf("Expected the queried response field, got %q", output)
+	}
+}
+
+func TestCachePurgeFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		olderThan time.Duration
+		endpoint  string
+		slug      string
+		want      cache.Filter
+		wantErr   bool
+	}{
+		{name: "no filters", wantErr: true},
+		{name: "negative age", olderThan: -time.Hour, wantErr: true},
+		{name: "unknown endpoint", endpoint: "nope", wantErr: true},
+		{name: "older than", olderThan: time.Hour, want: cache.Filter{OlderThan: time.Hour}},
+		{name: "endpoint by name", endpoint: "page", want: cache.Filter{Endpoint: api.EndpointPage}},
+		{name: "endpoint by path", endpoint: api.EndpointPage, want: cache.Filter{Endpoint: api.EndpointPage}},
+		{name: "slug", slug: "Go", want: cache.Filter{Slug: "Go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cachePurgeFilter(tt.olderThan, tt.endpoint, tt.slug)
+			if tt.wantErr {
+				if api.GetExitCode(err) != api.ExitInvalidArgs {
+					t.Errorf("Expected invalid args error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("cachePurgeFilter() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("cachePurgeFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachePurgeCommand(t *testing.T) {
+	c := withCache(t)
+
+	oldOlderThan := cachePurgeOlderThan
+	t.Cleanup(func() { cachePurgeOlderThan = oldOlderThan })
+	cachePurgeOlderThan = time.Hour
+
+	output, err := runCommand(t, cachePurgeCmd)
+	if err != nil {
+		t.Fatalf("cache purge error = %v", err)
+	}
+	if !strings.Contains(output, "Purged 1 cache entry.") {
+		t.Errorf("Unexpected output %q", output)
+	}
+
+	infos, _ := c.Entries()
+	if len(infos) != 1 || infos[0].Endpoint() != api.EndpointPage {
+		t.Errorf("Expected only the page entry to remain, got %+v", infos)
+	}
+}
+
+func TestCacheClearCommand(t *testing.T) {
+	c := withCache(t)
+
+	if _, err := runCommand(t, cacheClearCmd); err != nil {
+		t.Fatalf("cache clear error = %v", err)
+	}
+
+	infos, _ := c.Entries()
+	if len(infos) != 0 {
+		t.Errorf("Expected empty cache, got %d entries", len(infos))
+	}
+}
+
+func TestCacheStatsCommand(t *testing.T) {
+	withCache(t)
+
+	oldFormat := cacheStatsFormat
+	t.Cleanup(func() { cacheStatsFormat = oldFormat })
+	cacheStatsFormat = "json"
+
+	output, err := runCommand(t, cacheStatsCmd)
+	if err != nil {
+		t.Fatalf("cache stats error = %v", err)
+	}
+
+	var stats cache.Stats
+	if err := json.Unmarshal([]byte(output), &stats); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if stats.Entries != 2 || stats.Expired != 1 {
+		t.Errorf("Expected 2 entries with 1 expired, got %+v", stats)
+	}
+
+	oldOutput := cacheStatsOutput
+	t.Cleanup(func() { cacheStatsOutput = oldOutput })
+	cacheStatsFormat, cacheStatsOutput.fields = "tsv", "entries,expired"
+	output, err = runCommand(t, cacheStatsCmd)
+	if err != nil {
+		t.Fatalf("cache stats error = %v", err)
+	}
+	if want := "entries\texpired\n2\t1\n"; output != want {
+		t.Errorf("Expected %q, got %q", want, output)
+	}
+}
+
+func TestCacheWarmCommand(t *testing.T) {
+	fake := newFixtureFake(t)
+	withFakeClient(t, fake)
+	c := withCache(t)
+
+	oldFile, oldSearch := cacheWarmFile, cacheWarmSearch
+	t.Cleanup(func() { cacheWarmFile, cacheWarmSearch = oldFile, oldSearch })
+
+	slugFile := filepath.Join(t.TempDir(), "slugs.txt")
+	if err := os.WriteFile(slugFile, []byte("# pages to read\nPython_programming_language\n\nMissing_page\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cacheWarmFile = slugFile
+
+	_, err := runCommand(t, cacheWarmCmd, "Python_programming_language")
+	if err == nil || !strings.Contains(err.Error(), "failed to warm 1 page") {
+		t.Errorf("Expected Missing_page to fail, got %v", err)
+	}
+
+	req := cache.PageRequest("Python_programming_language", true, true)
+	if _, found := c.Get(c.Key(req)); !found {
+		t.Error("Expected page with content to be cached")
+	}
+	// The slug given twice is fetched once per variant, and the missing page
+	// is given up on after its first fetch
+	if got := fake.Calls(apitest.MethodPage); got != 3 {
+		t.Errorf("Expected 3 page fetches, got %d", got)
+	}
+}
+
+func TestCacheWarmCommandRequiresSlugs(t *testing.T) {
+	withFakeClient(t, apitest.New())
+	withCache(t)
+
+	_, err := runCommand(t, cacheWarmCmd)
+	if api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Expected invalid args error, got %v", err)
+	}
+}
+
+func TestCacheExportImportCommands(t *testing.T) {
+	withCache(t)
+	bundle := filepath.Join(t.TempDir(), "cache.tar.gz")
+
+	output, err := runCommand(t, cacheExportCmd, bundle)
+	if err != nil {
+		t.Fatalf("cache export error = %v", err)
+	}
+	if !strings.Contains(output, "Exported 2 cache entries") {
+		t.Errorf("Unexpected output %q", output)
+	}
+
+	// Import into an empty cache
+	old := appCache
+	t.Cleanup(func() { appCache = old })
+	appCache = cache.New(t.TempDir(), 3600)
+
+	output, err = runCommand(t, cacheImportCmd, bundle)
+	if err != nil {
+		t.Fatalf("cache import error = %v", err)
+	}
+	if !strings.Contains(output, "Imported 2 cache entries; kept 0 newer local entries.") {
+		t.Errorf("Unexpected output %q", output)
+	}
+
+	infos, _ := appCache.Entries()
+	if len(infos) != 2 {
+		t.Errorf("Expected 2 imported entries, got %d", len(infos))
+	}
+
+	_, err = runCommand(t, cacheImportCmd, filepath.Join(t.TempDir(), "missing.tar.gz"))
+	if api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Expected invalid args error for a missing bundle, got %v", err)
+	}
+}
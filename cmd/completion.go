@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds the API calls made while completing arguments, so
+// that a slow or unreachable API never stalls the shell
+const completionTimeout = 1500 * time.Millisecond
+
+// slugCompletionLimit is the number of typeahead suggestions offered when
+// completing a slug
+const slugCompletionLimit = 10
+
+// completionService returns the service used for dynamic completions,
+// setting up the client and cache from the flags typed so far. Cached
+// answers are used as usual, so completing the same prefix again does not
+// reach the API.
+func completionService() (*service.Service, bool) {
+	if appClient == nil {
+		if err := setup(); err != nil {
+			cobra.CompDebugln("setup failed: "+err.Error(), false)
+			return nil, false
+		}
+	}
+	return getService(), true
+}
+
+// completionContext returns a context for completion API calls, bounded by
+// completionTimeout
+func completionContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, completionTimeout)
+}
+
+// completeSlugs completes the slug argument of page commands from the
+// typeahead suggestions for what has been typed
+func completeSlugs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || toComplete == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	svc, ok := completionService()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ctx, cancel := completionContext(cmd)
+	defer cancel()
+
+	slugs, err := svc.SlugSuggestions(ctx, toComplete, slugCompletionLimit)
+	if err != nil {
+		cobra.CompDebugln("typeahead failed: "+err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return slugs, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEditStatuses completes the comma-separated --status flag of the
+// edits command with the statuses listed in the API constants, falling back
+// to api.DefaultEditStatuses when they cannot be fetched
+func completeEditStatuses(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	statuses := api.DefaultEditStatuses
+	if svc, ok := completionService(); ok {
+		ctx, cancel := completionContext(cmd)
+		defer cancel()
+
+		if fetched, err := svc.EditStatuses(ctx); err == nil {
+			statuses = fetched
+		} else {
+			cobra.CompDebugln("constants failed: "+err.Error(), false)
+		}
+	}
+
+	return completeList(statuses, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeList completes the last item of a comma-separated list, leaving
+// out values already given
+func completeList(values []string, toComplete string) []string {
+	var given []string
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+		given = strings.Split(toComplete[:i], ",")
+	}
+
+	var completions []string
+	for _, v := range values {
+		if !slices.Contains(given, v) {
+			completions = append(completions, prefix+v)
+		}
+	}
+	return completions
+}
+
+// completeFormats completes a --format flag with the given formats
+func completeFormats(formats []string) cobra.CompletionFunc {
+	return cobra.FixedCompletions(formats, cobra.ShellCompDirectiveNoFileComp)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/apitest"
+	"github.com/spf13/cobra"
+)
+
+func TestCompleteSlugs(t *testing.T) {
+	withFakeClient(t, newFixtureFake(t))
+
+	got, directive := completeSlugs(pageCmd, nil, "Python_s")
+	want := []string{"Python_syntax", "Python_standard_library"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("completeSlugs() = %v, want %v", got, want)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("Expected file completion to be disabled, got directive %d", directive)
+	}
+
+	// Only the first argument is a slug
+	if got, _ := completeSlugs(pageCmd, []string{"Go"}, "Python"); len(got) != 0 {
+		t.Errorf("Expected no completions for a second argument, got %v", got)
+	}
+}
+
+func TestCompleteEditStatuses(t *testing.T) {
+	fake := apitest.New()
+	fake.Constants = api.ConstantsResponse{
+		"editRequestStatuses": []interface{}{
+			"EDIT_REQUEST_STATUS_UNSPECIFIED",
+			"EDIT_REQUEST_STATUS_APPROVED",
+			"EDIT_REQUEST_STATUS_REJECTED",
+		},
+	}
+	withFakeClient(t, fake)
+
+	got, _ := completeEditStatuses(editsCmd, nil, "approved,")
+	if want := []string{"approved,rejected"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completeEditStatuses() = %v, want %v", got, want)
+	}
+
+	// Falls back to the known statuses when the constants are unavailable
+	fake.SetError(apitest.MethodConstants, errors.New("unavailable"))
+	got, _ = completeEditStatuses(editsCmd, nil, "")
+	if !reflect.DeepEqual(got, api.DefaultEditStatuses) {
+		t.Errorf("completeEditStatuses() = %v, want %v", got, api.DefaultEditStatuses)
+	}
+}
+
+func TestCompleteList(t *testing.T) {
+	values := []string{"approved", "implemented", "pending"}
+
+	tests := []struct {
+		toComplete string
+		want       []string
+	}{
+		{"", []string{"approved", "implemented", "pending"}},
+		{"app", []string{"approved", "implemented", "pending"}},
+		{"pending,", []string{"pending,approved", "pending,implemented"}},
+		{"pending,approved,im", []string{"pending,approved,implemented"}},
+	}
+
+	for _, tt := range tests {
+		if got := completeList(values, tt.toComplete); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("completeList(%q) = %v, want %v", tt.toComplete, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsSetup(t *testing.T) {
+	// Cobra adds the completion command when executing, so build its shape
+	completion := &cobra.Command{Use: "completion"}
+	bash := &cobra.Command{Use: "bash"}
+	completion.AddCommand(bash)
+
+	tests := []struct {
+		cmd  *cobra.Command
+		want bool
+	}{
+		{pageCmd, true},
+		{cacheListCmd, true},
+		{versionCmd, false},
+		{bash, false},
+	}
+	for _, tt := range tests {
+		if got := needsSetup(tt.cmd); got != tt.want {
+			t.Errorf("needsSetup(%s) = %v, want %v", tt.cmd.CommandPath(), got, tt.want)
+		}
+	}
+}
@@ -1,15 +1,11 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
 	"os"
-	"strings"
-	"time"
 
 	"github.com/grokipedia/cli/internal/api"
 	"github.com/grokipedia/cli/internal/formatter"
-	"github.com/rodaine/table"
+	"github.com/grokipedia/cli/internal/service"
 	"github.com/spf13/cobra"
 )
 
@@ -19,6 +15,10 @@ var (
 	editsExcludeUser []string
 	editsCounts      bool
 	editsFormat      string
+	editsOutput      outputFlags
+	editsNoHeader    bool
+	editsAll         bool
+	editsMax         int
 )
 
 // editsCmd represents the edits command
@@ -29,58 +29,27 @@ var editsCmd = &cobra.Command{
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Validate format
-		allowedFormats := []string{"table", "json"}
-		if err := formatter.ValidateFormat(editsFormat, allowedFormats); err != nil {
+		if err := formatter.ValidateFormat(editsFormat, formatter.TabularFormats); err != nil {
 			return &api.InvalidArgsError{Message: err.Error()}
 		}
 
-		// Parse status filter
-		var statusList []string
-		if editsStatus != "" {
-			statusList = strings.Split(editsStatus, ",")
-			for i := range statusList {
-				statusList[i] = strings.TrimSpace(statusList[i])
-			}
+		opts := service.EditsOptions{
+			Limit:         editsLimit,
+			Status:        editsStatus,
+			ExcludeUsers:  editsExcludeUser,
+			IncludeCounts: editsCounts,
+			All:           editsAll,
+			Max:           editsMax,
 		}
-
-		// Build cache key params
-		cacheParams := map[string]interface{}{
-			"limit":         editsLimit,
-			"includeCounts": editsCounts,
-		}
-		if len(statusList) > 0 {
-			cacheParams["status"] = editsStatus
-		}
-		if len(editsExcludeUser) > 0 {
-			cacheParams["excludeUsers"] = strings.Join(editsExcludeUser, ",")
-		}
-
-		// Check cache first
-		cacheKey := ""
-		if c := getCache(); c != nil {
-			cacheKey = c.GenerateKey("/api/list-edit-requests", cacheParams)
-			if data, found := c.Get(cacheKey); found {
-				var cached api.EditsResponse
-				if err := json.Unmarshal(data, &cached); err == nil {
-					return outputEditsResults(&cached, editsFormat)
-				}
-			}
+		if editsOutput.streaming(editsFormat) {
+			return formatter.WriteNDJSON(os.Stdout, getService().EditsStream(cmd.Context(), opts))
 		}
 
-		// Make API request
-		client := getClient()
-		results, err := client.Edits(editsLimit, statusList, editsExcludeUser, editsCounts)
+		results, err := getService().Edits(cmd.Context(), opts)
 		if err != nil {
 			return err
 		}
 
-		// Cache the response
-		if c := getCache(); c != nil && cacheKey != "" {
-			if data, err := json.Marshal(results); err == nil {
-				_ = c.Set(cacheKey, data)
-			}
-		}
-
 		return outputEditsResults(results, editsFormat)
 	},
 }
@@ -99,49 +68,21 @@ func init() {
 	editsCmd.Flags().StringVar(&editsStatus, "status", "", "Filter by status (comma-separated: approved,implemented,pending)")
 	editsCmd.Flags().StringArrayVar(&editsExcludeUser, "exclude-user", []string{}, "Exclude edits by username (repeatable)")
 	editsCmd.Flags().BoolVar(&editsCounts, "counts", true, "Include count metadata")
-	editsCmd.Flags().StringVar(&editsFormat, "format", "table", "Output format: table, json")
+	editsCmd.Flags().StringVar(&editsFormat, "format", "table", "Output format: table, json, ndjson, yaml, markdown, plain, list, csv, tsv, template")
+	editsOutput.addTemplateFlags(editsCmd)
+	editsOutput.addSelectionFlags(editsCmd)
+	editsCmd.Flags().BoolVar(&editsNoHeader, "no-header", false, "Omit the header row in csv and tsv output")
+	editsCmd.Flags().BoolVar(&editsAll, "all", false, "Fetch every page of edit requests, using --limit as the page size")
+	editsCmd.Flags().IntVar(&editsMax, "max", 0, "Stop after this many edit requests when using --all (0 for no limit)")
+	_ = editsCmd.RegisterFlagCompletionFunc("status", completeEditStatuses)
+	_ = editsCmd.RegisterFlagCompletionFunc("format", completeFormats(formatter.TabularFormats))
 }
 
 // outputEditsResults outputs edit results in the specified format
 func outputEditsResults(results *api.EditsResponse, format string) error {
-	switch format {
-	case "json":
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(results)
-
-	case "table":
-		if len(results.EditRequests) == 0 {
-			fmt.Println("No edit requests found.")
-			return nil
-		}
-
-		tbl := table.New("ID", "Slug", "Status", "Editor", "Timestamp").WithWriter(os.Stdout)
-		if shouldUseColor() {
-			tbl.WithHeaderFormatter(func(format string, vals ...interface{}) string {
-				return fmt.Sprintf("\033[1m%s\033[0m", fmt.Sprintf(format, vals...))
-			})
-		}
-
-		for _, edit := range results.EditRequests {
-			timestamp := time.Unix(edit.Timestamp, 0).Format("2006-01-02 15:04")
-			status := strings.TrimPrefix(edit.Status, "EDIT_REQUEST_STATUS_")
-			tbl.AddRow(edit.ID, edit.Slug, status, edit.Editor, timestamp)
-		}
-
-		tbl.Print()
-
-		if editsCounts {
-			fmt.Printf("\nTotal: %d", results.TotalCount)
-			if results.HasMore {
-				fmt.Print(" (more available)")
-			}
-			fmt.Println()
-		}
-
-		return nil
-
-	default:
-		return &api.InvalidArgsError{Message: fmt.Sprintf("invalid format '%s'", format)}
+	opts, err := editsOutput.options(format, editsNoHeader)
+	if err != nil {
+		return err
 	}
+	return service.WriteEdits(os.Stdout, results, format, editsCounts, opts)
 }
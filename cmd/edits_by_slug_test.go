@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/apitest"
 )
 
 func TestEditsBySlugCommandValidation(t *testing.T) {
@@ -209,3 +211,31 @@ func TestEditsBySlugMultipleStatuses(t *testing.T) {
 		}
 	}
 }
+
+func TestEditsBySlugCommandAllPages(t *testing.T) {
+	fake := apitest.New()
+	for i := 0; i < 7; i++ {
+		fake.EditRequests = append(fake.EditRequests, api.EditRequest{
+			ID:     fmt.Sprintf("req-%03d", i),
+			Slug:   "Go",
+			Status: "EDIT_REQUEST_STATUS_PENDING",
+		})
+	}
+	withFakeClient(t, fake)
+
+	oldAll, oldLimit := editsBySlugAll, editsBySlugLimit
+	t.Cleanup(func() { editsBySlugAll, editsBySlugLimit = oldAll, oldLimit })
+	editsBySlugAll, editsBySlugLimit = true, 3
+
+	output, err := runCommand(t, editsBySlugCmd, "Go")
+	if err != nil {
+		t.Fatalf("edits-by-slug --all error = %v", err)
+	}
+
+	if !strings.Contains(output, "req-006") {
+		t.Errorf("Expected last edit request in output, got %q", output)
+	}
+	if got := fake.Calls(apitest.MethodEditsBySlug); got != 3 {
+		t.Errorf("Expected 3 page requests, got %d", got)
+	}
+}
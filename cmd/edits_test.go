@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/apitest"
 )
 
 func TestEditsCommandValidation(t *testing.T) {
@@ -198,3 +201,60 @@ func TestEditsTimestampFormatting(t *testing.T) {
 		t.Logf("Timestamp formatting output: %s", output)
 	}
 }
+
+func TestEditsNDJSONStreamsAllPages(t *testing.T) {
+	fake := apitest.New()
+	for _, id := range []string{"req-001", "req-002", "req-003"} {
+		fake.EditRequests = append(fake.EditRequests, api.EditRequest{ID: id, Slug: "Go"})
+	}
+	withFakeClient(t, fake)
+
+	oldFormat, oldLimit, oldAll := editsFormat, editsLimit, editsAll
+	editsFormat, editsLimit, editsAll = "ndjson", 2, true
+	t.Cleanup(func() { editsFormat, editsLimit, editsAll = oldFormat, oldLimit, oldAll })
+
+	output, err := runCommand(t, editsCmd)
+	if err != nil {
+		t.Fatalf("edits error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected one line per edit request, got %q", output)
+	}
+	for i, line := range lines {
+		var edit api.EditRequest
+		if err := json.Unmarshal([]byte(line), &edit); err != nil {
+			t.Fatalf("Line %d is not JSON: %v", i, err)
+		}
+		if edit.ID != fake.EditRequests[i].ID {
+			t.Errorf("Line %d: expected %s, got %s", i, fake.EditRequests[i].ID, edit.ID)
+		}
+	}
+	if got := fake.Calls(apitest.MethodEdits); got != 2 {
+		t.Errorf("Expected 2 page requests, got %d", got)
+	}
+}
+
+func TestEditsQueryWithDateTemplate(t *testing.T) {
+	fake := apitest.New()
+	fake.EditRequests = []api.EditRequest{
+		{ID: "req-001", Slug: "Go", Timestamp: 1702000000},
+		{ID: "req-002", Slug: "Rust", Timestamp: 1701900000},
+	}
+	withFakeClient(t, fake)
+
+	oldFormat, oldOutput := editsFormat, editsOutput
+	t.Cleanup(func() { editsFormat, editsOutput = oldFormat, oldOutput })
+	editsFormat = "template"
+	editsOutput.query = `.editRequests[] | select(.slug == "Go")`
+	editsOutput.template = `{{.id}} {{date .timestamp}}`
+
+	output, err := runCommand(t, editsCmd)
+	if err != nil {
+		t.Fatalf("edits error = %v", err)
+	}
+	if want := "req-001 " + time.Unix(1702000000, 0).Format("2006-01-02 15:04"); output != want {
+		t.Errorf("Expected %q, got %q", want, output)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/formatter"
+	"github.com/grokipedia/cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// outputFlags holds the output flags of one command, so that commands
+// sharing the flag names do not share their values
+type outputFlags struct {
+	template     string
+	templateFile string
+	fields       string
+	query        string
+}
+
+// addSelectionFlags adds --fields and --query to cmd
+func (f *outputFlags) addSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.fields, "fields", "", "Comma-separated columns for table, csv and tsv output, as JSON paths (e.g. title,slug,stats.totalViews)")
+	cmd.Flags().StringVar(&f.query, "query", "", "jq-style expression applied to the response before formatting (e.g. '.results[] | select(.viewCount > 100)')")
+}
+
+// streaming reports whether a listing in format can be streamed, which
+// --query and --fields rule out because they need the whole response
+func (f *outputFlags) streaming(format string) bool {
+	return format == string(formatter.FormatNDJSON) && f.query == "" && f.fields == ""
+}
+
+// addTemplateFlags adds the flags of the template format to cmd
+func (f *outputFlags) addTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.template, "template", "", "Go text/template for --format template")
+	cmd.Flags().StringVar(&f.templateFile, "template-file", "", "File holding the template for --format template")
+}
+
+// options returns the formatter options for writing the command's output in
+// format, parsing --query and loading the template for the template format
+func (f *outputFlags) options(format string, noHeader bool) (formatter.Options, error) {
+	opts := formatter.Options{
+		Color:    shouldUseColor(),
+		NoHeader: noHeader,
+		Fields:   service.ParseFields(f.fields),
+	}
+	if f.query != "" {
+		q, err := service.ParseQuery(f.query)
+		if err != nil {
+			return opts, err
+		}
+		opts.Query = q
+	}
+	if format != string(formatter.FormatTemplate) {
+		return opts, nil
+	}
+
+	text := f.template
+	if text != "" && f.templateFile != "" {
+		return opts, &api.InvalidArgsError{Message: "--template and --template-file cannot be used together"}
+	}
+	if f.templateFile != "" {
+		data, err := os.ReadFile(f.templateFile)
+		if err != nil {
+			return opts, &api.InvalidArgsError{Message: err.Error()}
+		}
+		text = string(data)
+	} else if text == "" {
+		return opts, &api.InvalidArgsError{Message: "--format template requires --template or --template-file"}
+	}
+
+	tmpl, err := service.ParseTemplate(text)
+	if err != nil {
+		return opts, err
+	}
+	opts.Template = tmpl
+	return opts, nil
+}
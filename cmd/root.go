@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/grokipedia/cli/internal/api"
 	"github.com/grokipedia/cli/internal/cache"
 	"github.com/grokipedia/cli/internal/config"
+	"github.com/grokipedia/cli/internal/service"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
@@ -16,17 +21,38 @@ var (
 	apiURL    string
 	timeout   int
 	noCache   bool
+	offline   bool
 	cacheDir  string
 	cacheTTL  int
 	verbose   bool
 	debug     bool
 	colorMode string
 
+	maxAttempts   int
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+	retryStatuses []int
+	retryErrors   []string
+	rateLimit     float64
+	rateBurst     int
+
 	appConfig *config.Config
 	appCache  *cache.Cache
-	appClient *api.Client
+	appClient api.GrokipediaAPI
+
+	// storeCache is the cache opened by the cache commands when caching is
+	// disabled; see cacheStore
+	storeCache *cache.Cache
+
+	// backgroundCtx bounds the cache revalidations that outlive a command;
+	// see Execute. Nil means they are never cancelled.
+	backgroundCtx context.Context
 )
 
+// cacheCloseTimeout bounds how long Execute waits for background cache
+// work before cancelling it
+const cacheCloseTimeout = 5 * time.Second
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "grokipedia",
@@ -36,56 +62,141 @@ var rootCmd = &cobra.Command{
 Use the search command to find pages, the page command to view content,
 and other commands to interact with edit requests and API constants.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// Skip initialization for help and completion commands
-		if cmd.Name() == "help" || cmd.Name() == "completion" {
+		// Flags and arguments are valid by now, so later errors are not
+		// usage errors
+		cmd.SilenceUsage = true
+
+		if !needsSetup(cmd) {
 			return nil
 		}
+		return setup()
+	},
+}
 
-		// Load configuration
-		flags := config.GlobalFlags{
-			APIURL:     apiURL,
-			Timeout:    timeout,
-			NoCache:    noCache,
-			CacheDir:   cacheDir,
-			CacheTTL:   cacheTTL,
-			Verbose:    verbose,
-			Debug:      debug,
-			ConfigFile: cfgFile,
-			Color:      colorMode,
+// needsSetup reports whether cmd uses the configuration, cache or client.
+// Help, version and shell completion commands run without them; argument
+// completion sets them up itself, see completionService.
+func needsSetup(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case "help", "completion", "version", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return false
 		}
+	}
+	return true
+}
+
+// setup loads the configuration and creates the cache and API client from
+// the global flags
+func setup() error {
+	// Load configuration
+	flags := config.GlobalFlags{
+		APIURL:     apiURL,
+		Timeout:    timeout,
+		NoCache:    noCache,
+		Offline:    offline,
+		CacheDir:   cacheDir,
+		CacheTTL:   cacheTTL,
+		Verbose:    verbose,
+		Debug:      debug,
+		ConfigFile: cfgFile,
+		Color:      colorMode,
+
+		MaxAttempts:   maxAttempts,
+		RetryDelay:    retryDelay,
+		MaxRetryDelay: maxRetryDelay,
+		RetryStatuses: retryStatuses,
+		RetryErrors:   retryErrors,
+		RateLimit:     rateLimit,
+		RateBurst:     rateBurst,
+	}
 
-		var err error
-		appConfig, err = config.Load(flags)
+	var err error
+	appConfig, err = config.Load(flags)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if appConfig.Cache.Offline && (noCache || !appConfig.IsCacheEnabled()) {
+		return &api.InvalidArgsError{Message: "--offline needs the cache, which is disabled"}
+	}
+
+	// Initialize cache if enabled
+	if !noCache && appConfig.IsCacheEnabled() {
+		appCache, err = newCache(appConfig)
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return err
 		}
+	}
 
-		// Initialize cache if enabled
-		if !noCache && appConfig.IsCacheEnabled() {
-			appCache = cache.New(
-				appConfig.GetCacheDir(),
-				appConfig.GetCacheTTL(),
-			)
-		}
+	retry, err := retryPolicy(appConfig.API.Retry)
+	if err != nil {
+		return err
+	}
 
-		// Initialize API client
-		appClient = api.NewClient(api.ClientOptions{
-			BaseURL: appConfig.API.URL,
-			Timeout: appConfig.API.Timeout,
-			Verbose: verbose,
-			Debug:   debug,
-		})
+	// Initialize API client
+	appClient = api.NewClient(api.ClientOptions{
+		BaseURL: appConfig.API.URL,
+		Timeout: appConfig.API.Timeout,
+		Verbose: appConfig.Verbose,
+		Debug:   appConfig.Debug,
+		Retry:   retry,
+		RateLimit: api.RateLimit{
+			RequestsPerSecond: appConfig.API.RateLimit.RequestsPerSecond,
+			Burst:             appConfig.API.RateLimit.Burst,
+			StateFile:         appConfig.GetRateLimitFile(),
+			Warnings:          os.Stderr,
+		},
+	})
 
-		return nil
-	},
+	return nil
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// Commands receive a context that is cancelled on Ctrl-C or SIGTERM.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background cache revalidations outlive the command, but not an
+	// interrupt during it
+	background, cancelBackground := context.WithCancel(context.Background())
+	defer cancelBackground()
+	unlink := context.AfterFunc(ctx, cancelBackground)
+	backgroundCtx = background
+
+	err := rootCmd.ExecuteContext(ctx)
+
+	// Restore the default signal handling, so that an interrupt while the
+	// caches close ends the process
+	unlink()
+	stop()
+	closeCaches(cancelBackground)
+
 	if err != nil {
-		exitCode := api.GetExitCode(err)
-		os.Exit(exitCode)
+		os.Exit(api.GetExitCode(err))
+	}
+}
+
+// closeCaches lets background cache revalidations finish writing, then
+// compacts and releases the caches. Revalidations still running after
+// cacheCloseTimeout are cancelled with cancelBackground.
+func closeCaches(cancelBackground context.CancelFunc) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, c := range []*cache.Cache{appCache, storeCache} {
+			if c != nil {
+				_ = c.Close()
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(cacheCloseTimeout):
+		cancelBackground()
+		<-done
 	}
 }
 
@@ -93,15 +204,30 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.grokipedia/config.yml)")
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is $HOME/.grokipedia/config.yml) (env: GROKIPEDIA_CONFIG)")
 	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "API base URL (env: GROKIPEDIA_API_URL)")
 	rootCmd.PersistentFlags().IntVar(&timeout, "timeout", 0, "Request timeout in seconds (env: GROKIPEDIA_TIMEOUT)")
 	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Disable caching (env: GROKIPEDIA_NO_CACHE)")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Answer only from the cache, ignoring expiry, and exit with code 5 when a response is not cached (env: GROKIPEDIA_OFFLINE)")
 	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Cache directory (env: GROKIPEDIA_CACHE_DIR)")
-	rootCmd.PersistentFlags().IntVar(&cacheTTL, "cache-ttl", 0, "Cache TTL in seconds (env: GROKIPEDIA_CACHE_TTL)")
+	rootCmd.PersistentFlags().IntVar(&cacheTTL, "cache-ttl", 0, "Cache TTL in seconds for every endpoint, overriding cache.ttl_by_endpoint (env: GROKIPEDIA_CACHE_TTL)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output (env: GROKIPEDIA_VERBOSE)")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug output (env: GROKIPEDIA_DEBUG)")
 	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "Color mode: auto, always, never (env: GROKIPEDIA_COLOR)")
+	_ = rootCmd.RegisterFlagCompletionFunc("color", cobra.FixedCompletions([]string{"auto", "always", "never"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.PersistentFlags().IntVar(&maxAttempts, "max-attempts", 0, "Maximum attempts per request, 1 disables retries (env: GROKIPEDIA_MAX_ATTEMPTS)")
+	rootCmd.PersistentFlags().DurationVar(&retryDelay, "retry-delay", 0, "Base back-off delay between retries (env: GROKIPEDIA_RETRY_DELAY)")
+	rootCmd.PersistentFlags().DurationVar(&maxRetryDelay, "max-retry-delay", 0, "Maximum back-off delay between retries (env: GROKIPEDIA_MAX_RETRY_DELAY)")
+	rootCmd.PersistentFlags().IntSliceVar(&retryStatuses, "retry-status", nil, "HTTP status codes to retry (comma-separated) (env: GROKIPEDIA_RETRY_STATUSES)")
+	rootCmd.PersistentFlags().StringSliceVar(&retryErrors, "retry-errors", nil, "Network errors to retry (comma-separated: timeout,dns,refused,reset,aborted,broken-pipe,eof or none) (env: GROKIPEDIA_RETRY_ERRORS)")
+	rootCmd.PersistentFlags().Float64Var(&rateLimit, "rate-limit", 0, "Maximum requests per second shared by all running invocations, 0 disables (env: GROKIPEDIA_RATE_LIMIT)")
+	rootCmd.PersistentFlags().IntVar(&rateBurst, "rate-burst", 0, "Requests that may be sent at once before --rate-limit pacing applies (env: GROKIPEDIA_RATE_BURST)")
+
+	// Names used before the commands moved to cobra
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config-file", "", "Deprecated alias of --config")
+	rootCmd.PersistentFlags().StringVar(&apiURL, "apiurl", "", "Deprecated alias of --api-url")
+	_ = rootCmd.PersistentFlags().MarkHidden("config-file")
+	_ = rootCmd.PersistentFlags().MarkHidden("apiurl")
 }
 
 func initConfig() {
@@ -122,16 +248,66 @@ func shouldUseColor() bool {
 	}
 }
 
+// retryPolicy converts the configured retry settings into a client policy
+func retryPolicy(cfg config.RetryConfig) (api.RetryPolicy, error) {
+	errs, err := api.ParseRetryableErrors(cfg.Errors)
+	if err != nil {
+		return api.RetryPolicy{}, &api.InvalidArgsError{Message: err.Error()}
+	}
+	return api.RetryPolicy{
+		MaxAttempts:       cfg.MaxAttempts,
+		BaseDelay:         cfg.BaseDelay,
+		MaxDelay:          cfg.MaxDelay,
+		RetryableStatuses: cfg.Statuses,
+		RetryableErrors:   errs,
+	}, nil
+}
+
+// newCache builds the cache described by cfg on its configured backend
+func newCache(cfg *config.Config) (*cache.Cache, error) {
+	store, err := cache.NewStore(cfg.Cache.Backend, cfg.GetCacheDir())
+	if err != nil {
+		return nil, &api.InvalidArgsError{Message: err.Error()}
+	}
+	ttls, err := cache.EndpointTTLs(cfg.Cache.TTLByEndpoint)
+	if err != nil {
+		return nil, &api.InvalidArgsError{Message: err.Error()}
+	}
+	if err := cache.ValidateCompression(cfg.Cache.Compression); err != nil {
+		return nil, &api.InvalidArgsError{Message: err.Error()}
+	}
+
+	return cache.NewWithOptions(cfg.GetCacheDir(), cfg.GetCacheTTL(), cache.Options{
+		StaleIfError:         cfg.GetStaleIfError(),
+		StaleWhileRevalidate: cfg.GetStaleWhileRevalidate(),
+		Warnings:             os.Stderr,
+		Context:              backgroundCtx,
+		MaxSize:              cfg.Cache.MaxSize,
+		MaxEntries:           cfg.Cache.MaxEntries,
+		Store:                store,
+		TTLByEndpoint:        ttls,
+		Offline:              cfg.Cache.Offline,
+		Compression:          cfg.Cache.Compression,
+		Namespace:            cfg.API.URL,
+	}), nil
+}
+
 // getCache returns the cache instance if enabled
 func getCache() *cache.Cache {
 	return appCache
 }
 
 // getClient returns the API client
-func getClient() *api.Client {
+func getClient() api.GrokipediaAPI {
 	return appClient
 }
 
+// getService returns the service that runs commands through the client and
+// cache
+func getService() *service.Service {
+	return service.New(appClient, appCache)
+}
+
 // getConfig returns the loaded configuration
 func getConfig() *config.Config {
 	return appConfig
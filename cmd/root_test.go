@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"strings"
 	"testing"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/apitest"
+	"github.com/spf13/cobra"
 )
 
 func TestShouldUseColor(t *testing.T) {
@@ -127,3 +134,222 @@ func TestPersistentFlags(t *testing.T) {
 		}
 	}
 }
+
+func TestLegacyFlagAliases(t *testing.T) {
+	aliases := map[string]string{
+		"config-file": "config",
+		"apiurl":      "api-url",
+	}
+
+	for alias, name := range aliases {
+		flag := rootCmd.PersistentFlags().Lookup(alias)
+		if flag == nil {
+			t.Errorf("Expected alias --%s of --%s to exist", alias, name)
+			continue
+		}
+		if !flag.Hidden {
+			t.Errorf("Expected alias --%s to be hidden", alias)
+		}
+	}
+
+	old := apiURL
+	t.Cleanup(func() { apiURL = old })
+	if err := rootCmd.PersistentFlags().Set("apiurl", "https://example.com"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if apiURL != "https://example.com" {
+		t.Errorf("Expected --apiurl to set the API URL, got %q", apiURL)
+	}
+}
+
+func TestUsageOnlyForUsageErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantUsage bool
+	}{
+		{"missing argument", []string{"typeahead"}, true},
+		{"unknown flag", []string{"typeahead", "py", "--bogus"}, true},
+		{"runtime error", []string{"typeahead", "py", "--offline", "--no-cache"}, false},
+	}
+
+	oldConfig, oldOffline, oldNoCache := appConfig, offline, noCache
+	t.Cleanup(func() {
+		appConfig, offline, noCache = oldConfig, oldOffline, oldNoCache
+		typeaheadCmd.SilenceUsage = false
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeaheadCmd.SilenceUsage = false
+			var buf bytes.Buffer
+			rootCmd.SetOut(&buf)
+			rootCmd.SetErr(&buf)
+			rootCmd.SetArgs(tt.args)
+
+			if err := rootCmd.Execute(); err == nil {
+				t.Fatal("Expected an error")
+			}
+			if got := bytes.Contains(buf.Bytes(), []byte("Usage:")); got != tt.wantUsage {
+				t.Errorf("Expected usage printed = %v, got %v:\n%s", tt.wantUsage, got, buf.String())
+			}
+		})
+	}
+}
+
+func TestCommandsWithFakeClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      *cobra.Command
+		args     []string
+		setup    func(fake *apitest.Fake)
+		want     []string
+		reject   []string
+		wantCode int
+		calls    map[string]int
+	}{
+		{
+			name:  "search",
+			cmd:   searchCmd,
+			args:  []string{"python"},
+			want:  []string{"Python_programming_language", "JavaScript"},
+			calls: map[string]int{apitest.MethodSearch: 1},
+		},
+		{
+			name: "page",
+			cmd:  pageCmd,
+			args: []string{"Python_programming_language"},
+			want: []string{"# Python Programming Language"},
+		},
+		{
+			name:     "page not found",
+			cmd:      pageCmd,
+			args:     []string{"Missing_page"},
+			wantCode: api.ExitNotFound,
+		},
+		{
+			name: "typeahead defaults to json",
+			cmd:  typeaheadCmd,
+			args: []string{"python s"},
+			want: []string{"{\n  \"suggestions\": [\n    \"Python syntax\",\n    \"Python standard library\"\n  ]\n}\n"},
+		},
+		{
+			name: "constants",
+			cmd:  constantsCmd,
+			want: []string{`"apiVersion": "v1"`},
+		},
+		{
+			name: "edits",
+			cmd:  editsCmd,
+			want: []string{"req-001", "req-002"},
+		},
+		{
+			name: "edits rate limited",
+			cmd:  editsCmd,
+			setup: func(fake *apitest.Fake) {
+				fake.SetError(apitest.MethodEdits, &api.RateLimitError{RetryAfter: 30})
+			},
+			wantCode: api.ExitRateLimited,
+		},
+		{
+			name:   "edits-by-slug",
+			cmd:    editsBySlugCmd,
+			args:   []string{"JavaScript"},
+			want:   []string{"req-002"},
+			reject: []string{"req-001"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFixtureFake(t)
+			if tt.setup != nil {
+				tt.setup(fake)
+			}
+			withFakeClient(t, fake)
+
+			output, err := runCommand(t, tt.cmd, tt.args...)
+			if tt.wantCode != 0 {
+				if code := api.GetExitCode(err); code != tt.wantCode {
+					t.Fatalf("Expected exit code %d, got %d (%v)", tt.wantCode, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s error = %v", tt.cmd.Name(), err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected %q in output, got %q", want, output)
+				}
+			}
+			for _, reject := range tt.reject {
+				if strings.Contains(output, reject) {
+					t.Errorf("Expected no %q in output, got %q", reject, output)
+				}
+			}
+			for method, want := range tt.calls {
+				if got := fake.Calls(method); got != want {
+					t.Errorf("Expected %d %s calls, got %d", want, method, got)
+				}
+			}
+		})
+	}
+}
+
+// withFakeClient installs client as the API client for the duration of the
+// test and disables the cache so every call reaches the client
+func withFakeClient(t *testing.T, client api.GrokipediaAPI) {
+	t.Helper()
+
+	oldClient, oldCache, oldStore := appClient, appCache, storeCache
+	appClient, appCache, storeCache = client, nil, nil
+	t.Cleanup(func() {
+		appClient, appCache, storeCache = oldClient, oldCache, oldStore
+	})
+}
+
+// newFixtureFake creates a fake API client seeded from the repository
+// fixtures
+func newFixtureFake(t *testing.T) *apitest.Fake {
+	t.Helper()
+
+	f, err := apitest.NewFromFixtures("../testdata/fixtures")
+	if err != nil {
+		t.Fatalf("failed to load API fixtures: %v", err)
+	}
+	return f
+}
+
+// runCommand invokes cmd's RunE directly, bypassing PersistentPreRunE so the
+// client installed by withFakeClient is used, and returns captured stdout
+func runCommand(t *testing.T, cmd *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+
+	cmd.SetContext(context.Background())
+
+	var err error
+	output := captureOutput(t, func() {
+		err = cmd.RunE(cmd, args)
+	})
+	return output, err
+}
+
+func TestVersionCommand(t *testing.T) {
+	oldVersion := rootCmd.Version
+	t.Cleanup(func() { rootCmd.Version = oldVersion })
+	SetVersion("v1.2.3", "abc1234", "2025-01-01_00:00:00")
+
+	var buf bytes.Buffer
+	versionCmd.SetOut(&buf)
+	t.Cleanup(func() { versionCmd.SetOut(nil) })
+	versionCmd.Run(versionCmd, nil)
+
+	if got, want := buf.String(), "grokipedia v1.2.3 (abc1234) built 2025-01-01_00:00:00\n"; got != want {
+		t.Errorf("version output = %q, want %q", got, want)
+	}
+}
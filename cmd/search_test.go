@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/apitest"
 )
 
 func TestSearchCommandValidation(t *testing.T) {
@@ -134,6 +138,37 @@ func TestSearchOutputTable(t *testing.T) {
 	}
 }
 
+func TestSearchOutputCSV(t *testing.T) {
+	response := &api.SearchResponse{
+		Results: []api.SearchResult{
+			{Title: "Go, the language", Slug: "Go", RelevanceScore: 0.88, ViewCount: 500},
+		},
+	}
+
+	output := captureOutput(t, func() {
+		if err := outputSearchResults(response, "csv"); err != nil {
+			t.Errorf("outputSearchResults() error = %v", err)
+		}
+	})
+	want := "Title,Slug,Score,Views\n\"Go, the language\",Go,0.88,500\n"
+	if output != want {
+		t.Errorf("Expected %q, got %q", want, output)
+	}
+
+	searchNoHeader = true
+	t.Cleanup(func() { searchNoHeader = false })
+
+	output = captureOutput(t, func() {
+		if err := outputSearchResults(response, "tsv"); err != nil {
+			t.Errorf("outputSearchResults() error = %v", err)
+		}
+	})
+	want = "Go, the language\tGo\t0.88\t500\n"
+	if output != want {
+		t.Errorf("Expected %q, got %q", want, output)
+	}
+}
+
 func TestSearchEmptyResults(t *testing.T) {
 	response := &api.SearchResponse{
 		Results: []api.SearchResult{},
@@ -248,3 +283,141 @@ func captureOutput(t *testing.T, f func()) string {
 
 	return buf.String()
 }
+
+func TestSearchCommandAllPages(t *testing.T) {
+	fake := apitest.New()
+	for i := 0; i < 25; i++ {
+		fake.SearchResults = append(fake.SearchResults, api.SearchResult{
+			Title: fmt.Sprintf("Page %d", i),
+			Slug:  fmt.Sprintf("Page_%d", i),
+		})
+	}
+	withFakeClient(t, fake)
+
+	oldAll, oldMax, oldLimit, oldFormat := searchAll, searchMax, searchLimit, searchFormat
+	t.Cleanup(func() {
+		searchAll, searchMax, searchLimit, searchFormat = oldAll, oldMax, oldLimit, oldFormat
+	})
+	searchAll, searchMax, searchLimit, searchFormat = true, 20, 8, "json"
+
+	output, err := runCommand(t, searchCmd, "page")
+	if err != nil {
+		t.Fatalf("search --all error = %v", err)
+	}
+
+	var results api.SearchResponse
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if len(results.Results) != 20 {
+		t.Errorf("Expected 20 results capped by --max, got %d", len(results.Results))
+	}
+	if got := fake.Calls(apitest.MethodSearch); got != 3 {
+		t.Errorf("Expected 3 page requests, got %d", got)
+	}
+}
+
+func TestSearchCommandMaxRequiresAll(t *testing.T) {
+	withFakeClient(t, apitest.New())
+
+	oldMax := searchMax
+	t.Cleanup(func() { searchMax = oldMax })
+	searchMax = 5
+
+	_, err := runCommand(t, searchCmd, "python")
+	if api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Expected invalid args error, got %v", err)
+	}
+}
+
+func TestSearchCommandTemplate(t *testing.T) {
+	fake := apitest.New()
+	fake.SearchResults = []api.SearchResult{{Slug: "Go", ViewCount: 10}, {Slug: "Rust", ViewCount: 7}}
+	withFakeClient(t, fake)
+
+	oldFormat, oldOutput := searchFormat, searchOutput
+	t.Cleanup(func() { searchFormat, searchOutput = oldFormat, oldOutput })
+	searchFormat = "template"
+
+	_, err := runCommand(t, searchCmd, "go")
+	if api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Expected invalid args error without a template, got %v", err)
+	}
+
+	searchOutput.template = `{{range .Results}}{{.Slug}}{{"\t"}}{{.ViewCount}}{{"\n"}}{{end}}`
+	output, err := runCommand(t, searchCmd, "go")
+	if err != nil {
+		t.Fatalf("search error = %v", err)
+	}
+	if output != "Go\t10\nRust\t7\n" {
+		t.Errorf("Unexpected template output %q", output)
+	}
+
+	searchOutput.template = ""
+	searchOutput.templateFile = filepath.Join(t.TempDir(), "report.tmpl")
+	if err := os.WriteFile(searchOutput.templateFile, []byte("{{len .Results}} results\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output, err = runCommand(t, searchCmd, "go")
+	if err != nil {
+		t.Fatalf("search error = %v", err)
+	}
+	if output != "2 results\n" {
+		t.Errorf("Unexpected template file output %q", output)
+	}
+}
+
+func TestOutputFlagsPerCommand(t *testing.T) {
+	oldSearch, oldPage := searchOutput, pageOutput
+	t.Cleanup(func() { searchOutput, pageOutput = oldSearch, oldPage })
+
+	if err := searchCmd.Flags().Set("template", "{{.}}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := searchCmd.Flags().Set("query", ".results"); err != nil {
+		t.Fatal(err)
+	}
+	if searchOutput.template != "{{.}}" || searchOutput.query != ".results" {
+		t.Errorf("Expected the search flags to be set, got %+v", searchOutput)
+	}
+	if pageOutput != (outputFlags{}) {
+		t.Errorf("Expected the page flags to be unaffected, got %+v", pageOutput)
+	}
+}
+
+func TestSearchCommandQueryAndFields(t *testing.T) {
+	fake := apitest.New()
+	fake.SearchResults = []api.SearchResult{
+		{Title: "Go", Slug: "Go", ViewCount: 500},
+		{Title: "Rust", Slug: "Rust", ViewCount: 90},
+		{Title: "Python", Slug: "Python", ViewCount: 1200},
+	}
+	withFakeClient(t, fake)
+
+	oldFormat, oldOutput := searchFormat, searchOutput
+	t.Cleanup(func() { searchFormat, searchOutput = oldFormat, oldOutput })
+
+	searchFormat, searchOutput.fields = "csv", "slug,viewCount"
+	output, err := runCommand(t, searchCmd, "lang")
+	if err != nil {
+		t.Fatalf("search error = %v", err)
+	}
+	if want := "slug,viewCount\nGo,500\nRust,90\nPython,1200\n"; output != want {
+		t.Errorf("Expected %q, got %q", want, output)
+	}
+
+	searchFormat, searchOutput.query = "ndjson", ".results[] | select(.viewCount > 100)"
+	searchOutput.fields = ""
+	output, err = runCommand(t, searchCmd, "lang")
+	if err != nil {
+		t.Fatalf("search error = %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(output), "\n"); len(lines) != 2 || !strings.Contains(lines[1], `"slug":"Python"`) {
+		t.Errorf("Expected the two popular results, got %q", output)
+	}
+
+	searchOutput.query = ".results[] | frobnicate"
+	if _, err := runCommand(t, searchCmd, "lang"); api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Expected invalid args error for a bad query, got %v", err)
+	}
+}
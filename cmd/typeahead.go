@@ -1,18 +1,19 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
 	"os"
 
 	"github.com/grokipedia/cli/internal/api"
 	"github.com/grokipedia/cli/internal/formatter"
+	"github.com/grokipedia/cli/internal/service"
 	"github.com/spf13/cobra"
 )
 
 var (
-	typeaheadLimit  int
-	typeaheadFormat string
+	typeaheadLimit    int
+	typeaheadFormat   string
+	typeaheadOutput   outputFlags
+	typeaheadNoHeader bool
 )
 
 // typeaheadCmd represents the typeahead command
@@ -22,43 +23,16 @@ var typeaheadCmd = &cobra.Command{
 	Long:  `Retrieve search suggestions as you type.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		query := args[0]
-
 		// Validate format
-		allowedFormats := []string{"list", "json"}
-		if err := formatter.ValidateFormat(typeaheadFormat, allowedFormats); err != nil {
+		if err := formatter.ValidateFormat(typeaheadFormat, formatter.TabularFormats); err != nil {
 			return &api.InvalidArgsError{Message: err.Error()}
 		}
 
-		// Check cache first
-		cacheKey := ""
-		if c := getCache(); c != nil {
-			cacheKey = c.GenerateKey("/api/typeahead", map[string]interface{}{
-				"q":     query,
-				"limit": typeaheadLimit,
-			})
-			if data, found := c.Get(cacheKey); found {
-				var cached api.TypeaheadResponse
-				if err := json.Unmarshal(data, &cached); err == nil {
-					return outputTypeaheadResults(&cached, typeaheadFormat)
-				}
-			}
-		}
-
-		// Make API request
-		client := getClient()
-		results, err := client.Typeahead(query, typeaheadLimit)
+		results, err := getService().Typeahead(cmd.Context(), args[0], typeaheadLimit)
 		if err != nil {
 			return err
 		}
 
-		// Cache the response
-		if c := getCache(); c != nil && cacheKey != "" {
-			if data, err := json.Marshal(results); err == nil {
-				_ = c.Set(cacheKey, data)
-			}
-		}
-
 		return outputTypeaheadResults(results, typeaheadFormat)
 	},
 }
@@ -66,25 +40,18 @@ var typeaheadCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(typeaheadCmd)
 
-	typeaheadCmd.Flags().IntVar(&typeaheadLimit, "limit", 5, "Maximum number of suggestions (1-50)")
-	typeaheadCmd.Flags().StringVar(&typeaheadFormat, "format", "list", "Output format: list, json")
+	typeaheadCmd.Flags().IntVar(&typeaheadLimit, "limit", 10, "Maximum number of suggestions (1-50)")
+	typeaheadCmd.Flags().StringVar(&typeaheadFormat, "format", "json", "Output format: table, json, ndjson, yaml, markdown, plain, list, csv, tsv, template")
+	typeaheadOutput.addTemplateFlags(typeaheadCmd)
+	typeaheadCmd.Flags().BoolVar(&typeaheadNoHeader, "no-header", false, "Omit the header row in csv and tsv output")
+	_ = typeaheadCmd.RegisterFlagCompletionFunc("format", completeFormats(formatter.TabularFormats))
 }
 
 // outputTypeaheadResults outputs typeahead results in the specified format
 func outputTypeaheadResults(results *api.TypeaheadResponse, format string) error {
-	switch format {
-	case "json":
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(results)
-
-	case "list":
-		for _, suggestion := range results.Suggestions {
-			fmt.Println(suggestion)
-		}
-		return nil
-
-	default:
-		return &api.InvalidArgsError{Message: fmt.Sprintf("invalid format '%s'", format)}
+	opts, err := typeaheadOutput.options(format, typeaheadNoHeader)
+	if err != nil {
+		return err
 	}
+	return service.Write(os.Stdout, results, format, opts)
 }
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintf(cmd.OutOrStdout(), "grokipedia %s\n", rootCmd.Version)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.SetVersionTemplate("grokipedia {{.Version}}\n")
+	SetVersion("dev", "unknown", "unknown")
+}
+
+// SetVersion records the build information printed by the version command
+// and the --version flag
+func SetVersion(version, gitCommit, buildTime string) {
+	rootCmd.Version = fmt.Sprintf("%s (%s) built %s", version, gitCommit, buildTime)
+}
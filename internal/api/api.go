@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"strings"
+)
+
+// Endpoint paths of the Grokipedia API
+const (
+	EndpointSearch      = "/api/full-text-search"
+	EndpointPage        = "/api/page"
+	EndpointTypeahead   = "/api/typeahead"
+	EndpointConstants   = "/api/constants"
+	EndpointEdits       = "/api/list-edit-requests"
+	EndpointEditsBySlug = "/api/list-edit-requests-by-slug"
+)
+
+// EndpointNames maps the command name that refers to an endpoint in flags
+// and configuration to the endpoint path
+var EndpointNames = map[string]string{
+	"search":        EndpointSearch,
+	"page":          EndpointPage,
+	"typeahead":     EndpointTypeahead,
+	"constants":     EndpointConstants,
+	"edits":         EndpointEdits,
+	"edits-by-slug": EndpointEditsBySlug,
+}
+
+// EndpointPath resolves an endpoint given either by command name or by path
+func EndpointPath(name string) (string, bool) {
+	if path, ok := EndpointNames[name]; ok {
+		return path, true
+	}
+	for _, path := range EndpointNames {
+		if path == name || path == "/"+strings.TrimPrefix(name, "/") {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// EndpointName returns the command name for an endpoint path, or the path
+// itself if it is not a known endpoint
+func EndpointName(path string) string {
+	for name, p := range EndpointNames {
+		if p == path {
+			return name
+		}
+	}
+	return path
+}
+
+// GrokipediaAPI is the set of Grokipedia endpoints used by the CLI.
+// *Client implements it over HTTP; the apitest package provides an
+// in-memory fake for tests.
+type GrokipediaAPI interface {
+	SearchContext(ctx context.Context, query string, limit, offset int) (*SearchResponse, error)
+	PageContext(ctx context.Context, slug string, includeContent, validateLinks bool) (*PageResponse, error)
+	TypeaheadContext(ctx context.Context, query string, limit int) (*TypeaheadResponse, error)
+	ConstantsContext(ctx context.Context) (ConstantsResponse, error)
+	EditsContext(ctx context.Context, limit int, status []string, excludeUsers []string, includeCounts bool) (*EditsResponse, error)
+	EditsPageContext(ctx context.Context, limit, offset int, status []string, excludeUsers []string, includeCounts bool) (*EditsResponse, error)
+	EditsBySlugContext(ctx context.Context, slug string, limit, offset int) (*EditsBySlugResponse, error)
+}
+
+var _ GrokipediaAPI = (*Client)(nil)
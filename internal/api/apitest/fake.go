@@ -0,0 +1,258 @@
+// Package apitest provides an in-memory implementation of api.GrokipediaAPI
+// for tests that should not depend on an HTTP server.
+package apitest
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/grokipedia/cli/internal/api"
+)
+
+// Method names accepted by SetError and Calls
+const (
+	MethodSearch      = "Search"
+	MethodPage        = "Page"
+	MethodTypeahead   = "Typeahead"
+	MethodConstants   = "Constants"
+	MethodEdits       = "Edits"
+	MethodEditsBySlug = "EditsBySlug"
+)
+
+// Fake is a programmable in-memory implementation of api.GrokipediaAPI.
+//
+// Responses are derived from the seeded data fields. Any endpoint can be
+// replaced wholesale by setting the matching *Func hook, or made to fail
+// with SetError. Seed fields and hooks must be set before the fake is used
+// concurrently.
+type Fake struct {
+	// SearchResults is returned by Search, paginated by limit and offset
+	SearchResults []api.SearchResult
+	// Pages maps slugs to page data; unknown slugs report Found=false
+	Pages map[string]api.PageData
+	// Suggestions is returned by Typeahead, filtered by case-insensitive prefix
+	Suggestions []string
+	// Constants is returned by Constants
+	Constants api.ConstantsResponse
+	// EditRequests backs both Edits and EditsBySlug
+	EditRequests []api.EditRequest
+
+	SearchFunc      func(ctx context.Context, query string, limit, offset int) (*api.SearchResponse, error)
+	PageFunc        func(ctx context.Context, slug string, includeContent, validateLinks bool) (*api.PageResponse, error)
+	TypeaheadFunc   func(ctx context.Context, query string, limit int) (*api.TypeaheadResponse, error)
+	ConstantsFunc   func(ctx context.Context) (api.ConstantsResponse, error)
+	EditsFunc       func(ctx context.Context, limit, offset int, status []string, excludeUsers []string, includeCounts bool) (*api.EditsResponse, error)
+	EditsBySlugFunc func(ctx context.Context, slug string, limit, offset int) (*api.EditsBySlugResponse, error)
+
+	mu    sync.Mutex
+	errs  map[string]error
+	calls map[string]int
+}
+
+var _ api.GrokipediaAPI = (*Fake)(nil)
+
+// New creates an empty Fake
+func New() *Fake {
+	return &Fake{
+		Pages:     make(map[string]api.PageData),
+		Constants: api.ConstantsResponse{},
+	}
+}
+
+// SetError makes every subsequent call to method fail with err.
+// Passing a nil err clears a previously injected error.
+func (f *Fake) SetError(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.errs == nil {
+		f.errs = make(map[string]error)
+	}
+	if err == nil {
+		delete(f.errs, method)
+		return
+	}
+	f.errs[method] = err
+}
+
+// Calls returns how many times method has been invoked
+func (f *Fake) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// begin records a call and returns the context or injected error, if any
+func (f *Fake) begin(ctx context.Context, method string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[method]++
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.errs[method]
+}
+
+// SearchContext implements api.GrokipediaAPI
+func (f *Fake) SearchContext(ctx context.Context, query string, limit, offset int) (*api.SearchResponse, error) {
+	if err := f.begin(ctx, MethodSearch); err != nil {
+		return nil, err
+	}
+	if f.SearchFunc != nil {
+		return f.SearchFunc(ctx, query, limit, offset)
+	}
+
+	return &api.SearchResponse{
+		Results:    paginate(f.SearchResults, limit, offset),
+		TotalCount: len(f.SearchResults),
+		Facets:     []interface{}{},
+	}, nil
+}
+
+// PageContext implements api.GrokipediaAPI
+func (f *Fake) PageContext(ctx context.Context, slug string, includeContent, validateLinks bool) (*api.PageResponse, error) {
+	if err := f.begin(ctx, MethodPage); err != nil {
+		return nil, err
+	}
+	if f.PageFunc != nil {
+		return f.PageFunc(ctx, slug, includeContent, validateLinks)
+	}
+
+	page, ok := f.Pages[slug]
+	if !ok {
+		return &api.PageResponse{Found: false}, nil
+	}
+	if !includeContent {
+		page.Content = ""
+	}
+	return &api.PageResponse{Page: page, Found: true}, nil
+}
+
+// TypeaheadContext implements api.GrokipediaAPI
+func (f *Fake) TypeaheadContext(ctx context.Context, query string, limit int) (*api.TypeaheadResponse, error) {
+	if err := f.begin(ctx, MethodTypeahead); err != nil {
+		return nil, err
+	}
+	if f.TypeaheadFunc != nil {
+		return f.TypeaheadFunc(ctx, query, limit)
+	}
+
+	prefix := strings.ToLower(query)
+	suggestions := []string{}
+	for _, s := range f.Suggestions {
+		if strings.HasPrefix(strings.ToLower(s), prefix) {
+			suggestions = append(suggestions, s)
+		}
+	}
+	return &api.TypeaheadResponse{Suggestions: paginate(suggestions, limit, 0)}, nil
+}
+
+// ConstantsContext implements api.GrokipediaAPI
+func (f *Fake) ConstantsContext(ctx context.Context) (api.ConstantsResponse, error) {
+	if err := f.begin(ctx, MethodConstants); err != nil {
+		return nil, err
+	}
+	if f.ConstantsFunc != nil {
+		return f.ConstantsFunc(ctx)
+	}
+
+	result := make(api.ConstantsResponse, len(f.Constants))
+	for k, v := range f.Constants {
+		result[k] = v
+	}
+	return result, nil
+}
+
+// EditsContext implements api.GrokipediaAPI
+func (f *Fake) EditsContext(ctx context.Context, limit int, status []string, excludeUsers []string, includeCounts bool) (*api.EditsResponse, error) {
+	return f.EditsPageContext(ctx, limit, 0, status, excludeUsers, includeCounts)
+}
+
+// EditsPageContext implements api.GrokipediaAPI. Calls to it and to
+// EditsContext are both counted as MethodEdits, and both go to EditsFunc.
+func (f *Fake) EditsPageContext(ctx context.Context, limit, offset int, status []string, excludeUsers []string, includeCounts bool) (*api.EditsResponse, error) {
+	if err := f.begin(ctx, MethodEdits); err != nil {
+		return nil, err
+	}
+	if f.EditsFunc != nil {
+		return f.EditsFunc(ctx, limit, offset, status, excludeUsers, includeCounts)
+	}
+
+	wantStatus := make(map[string]bool, len(status))
+	for _, s := range status {
+		wantStatus["EDIT_REQUEST_STATUS_"+strings.ToUpper(s)] = true
+	}
+	excluded := make(map[string]bool, len(excludeUsers))
+	for _, u := range excludeUsers {
+		excluded[u] = true
+	}
+
+	matched := []api.EditRequest{}
+	for _, edit := range f.EditRequests {
+		if len(wantStatus) > 0 && !wantStatus[edit.Status] {
+			continue
+		}
+		if excluded[edit.Editor] {
+			continue
+		}
+		matched = append(matched, edit)
+	}
+
+	result := &api.EditsResponse{
+		EditRequests: paginate(matched, limit, offset),
+		HasMore:      limit > 0 && offset+limit < len(matched),
+	}
+	if includeCounts {
+		result.TotalCount = len(matched)
+		result.TotalCountUnfiltered = len(f.EditRequests)
+	}
+	return result, nil
+}
+
+// EditsBySlugContext implements api.GrokipediaAPI
+func (f *Fake) EditsBySlugContext(ctx context.Context, slug string, limit, offset int) (*api.EditsBySlugResponse, error) {
+	if err := f.begin(ctx, MethodEditsBySlug); err != nil {
+		return nil, err
+	}
+	if f.EditsBySlugFunc != nil {
+		return f.EditsBySlugFunc(ctx, slug, limit, offset)
+	}
+
+	matched := []api.EditRequest{}
+	for _, edit := range f.EditRequests {
+		if edit.Slug == slug {
+			matched = append(matched, edit)
+		}
+	}
+
+	return &api.EditsBySlugResponse{
+		EditRequests:         paginate(matched, limit, offset),
+		TotalCount:           len(matched),
+		HasMore:              limit > 0 && offset+limit < len(matched),
+		TotalCountUnfiltered: len(matched),
+	}, nil
+}
+
+// paginate returns the window of items selected by limit and offset.
+// A non-positive limit means no upper bound.
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	window := make([]T, end-offset)
+	copy(window, items[offset:end])
+	return window
+}
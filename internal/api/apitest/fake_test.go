@@ -0,0 +1,169 @@
+package apitest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grokipedia/cli/internal/api"
+)
+
+func TestNewFromFixtures(t *testing.T) {
+	f := newFixtureFake(t)
+
+	if len(f.SearchResults) != 2 {
+		t.Errorf("Expected 2 search results, got %d", len(f.SearchResults))
+	}
+	if _, ok := f.Pages["Python_programming_language"]; !ok {
+		t.Error("Expected fixture page to be seeded")
+	}
+	if len(f.Suggestions) != 3 {
+		t.Errorf("Expected 3 suggestions, got %d", len(f.Suggestions))
+	}
+	if f.Constants["apiVersion"] != "v1" {
+		t.Errorf("Expected apiVersion v1, got %v", f.Constants["apiVersion"])
+	}
+	// req-001 appears in both edit fixtures and must only be seeded once
+	if len(f.EditRequests) != 2 {
+		t.Errorf("Expected 2 edit requests, got %d", len(f.EditRequests))
+	}
+}
+
+func TestFakeSearchPagination(t *testing.T) {
+	f := newFixtureFake(t)
+
+	result, err := f.SearchContext(context.Background(), "python", 1, 1)
+	if err != nil {
+		t.Fatalf("SearchContext() error = %v", err)
+	}
+	if len(result.Results) != 1 || result.Results[0].Slug != "JavaScript" {
+		t.Errorf("Expected second result only, got %+v", result.Results)
+	}
+	if result.TotalCount != 2 {
+		t.Errorf("Expected TotalCount 2, got %d", result.TotalCount)
+	}
+}
+
+func TestFakePage(t *testing.T) {
+	f := newFixtureFake(t)
+	ctx := context.Background()
+
+	result, err := f.PageContext(ctx, "Python_programming_language", false, true)
+	if err != nil {
+		t.Fatalf("PageContext() error = %v", err)
+	}
+	if !result.Found {
+		t.Fatal("Expected page to be found")
+	}
+	if result.Page.Content != "" {
+		t.Error("Expected content to be omitted without includeContent")
+	}
+
+	result, err = f.PageContext(ctx, "Missing", true, true)
+	if err != nil {
+		t.Fatalf("PageContext() error = %v", err)
+	}
+	if result.Found {
+		t.Error("Expected unknown slug to report Found=false")
+	}
+}
+
+func TestFakeTypeahead(t *testing.T) {
+	f := newFixtureFake(t)
+
+	result, err := f.TypeaheadContext(context.Background(), "python s", 10)
+	if err != nil {
+		t.Fatalf("TypeaheadContext() error = %v", err)
+	}
+	if len(result.Suggestions) != 2 {
+		t.Errorf("Expected 2 suggestions, got %v", result.Suggestions)
+	}
+}
+
+func TestFakeEditsFilters(t *testing.T) {
+	f := newFixtureFake(t)
+	ctx := context.Background()
+
+	result, err := f.EditsContext(ctx, 10, []string{"approved"}, nil, true)
+	if err != nil {
+		t.Fatalf("EditsContext() error = %v", err)
+	}
+	if len(result.EditRequests) != 1 || result.EditRequests[0].ID != "req-002" {
+		t.Errorf("Expected only req-002, got %+v", result.EditRequests)
+	}
+
+	result, err = f.EditsPageContext(ctx, 1, 0, nil, nil, true)
+	if err != nil {
+		t.Fatalf("EditsPageContext() error = %v", err)
+	}
+	if !result.HasMore {
+		t.Error("Expected HasMore when limit is below the match count")
+	}
+
+	bySlug, err := f.EditsBySlugContext(ctx, "JavaScript", 10, 0)
+	if err != nil {
+		t.Fatalf("EditsBySlugContext() error = %v", err)
+	}
+	if bySlug.TotalCount != 1 {
+		t.Errorf("Expected 1 edit for JavaScript, got %d", bySlug.TotalCount)
+	}
+}
+
+func TestFakeSetErrorAndCalls(t *testing.T) {
+	f := New()
+	ctx := context.Background()
+
+	f.SetError(MethodConstants, &api.RateLimitError{RetryAfter: 5})
+
+	_, err := f.ConstantsContext(ctx)
+	var rateLimitErr *api.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Errorf("Expected RateLimitError, got %v", err)
+	}
+
+	f.SetError(MethodConstants, nil)
+	if _, err := f.ConstantsContext(ctx); err != nil {
+		t.Errorf("Expected error to be cleared, got %v", err)
+	}
+
+	if got := f.Calls(MethodConstants); got != 2 {
+		t.Errorf("Calls() = %d, want 2", got)
+	}
+}
+
+func TestFakeHookOverridesSeed(t *testing.T) {
+	f := newFixtureFake(t)
+	f.SearchFunc = func(ctx context.Context, query string, limit, offset int) (*api.SearchResponse, error) {
+		return &api.SearchResponse{TotalCount: 42}, nil
+	}
+
+	result, err := f.SearchContext(context.Background(), "anything", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchContext() error = %v", err)
+	}
+	if result.TotalCount != 42 {
+		t.Errorf("Expected hook response, got %+v", result)
+	}
+}
+
+func TestFakeCancelledContext(t *testing.T) {
+	f := newFixtureFake(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := f.SearchContext(ctx, "python", 10, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// newFixtureFake creates a Fake seeded from the repository fixtures
+func newFixtureFake(t *testing.T) *Fake {
+	t.Helper()
+
+	f, err := NewFromFixtures("../../../testdata/fixtures")
+	if err != nil {
+		t.Fatalf("failed to load API fixtures: %v", err)
+	}
+	return f
+}
@@ -0,0 +1,72 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grokipedia/cli/internal/api"
+)
+
+// NewFromFixtures creates a Fake seeded from the *_response.json fixtures in dir
+func NewFromFixtures(dir string) (*Fake, error) {
+	f := New()
+
+	var search api.SearchResponse
+	if err := readFixture(dir, "search_response.json", &search); err != nil {
+		return nil, err
+	}
+	f.SearchResults = search.Results
+
+	var page api.PageResponse
+	if err := readFixture(dir, "page_response.json", &page); err != nil {
+		return nil, err
+	}
+	if page.Found {
+		f.Pages[page.Page.Slug] = page.Page
+	}
+
+	var typeahead api.TypeaheadResponse
+	if err := readFixture(dir, "typeahead_response.json", &typeahead); err != nil {
+		return nil, err
+	}
+	f.Suggestions = typeahead.Suggestions
+
+	if err := readFixture(dir, "constants_response.json", &f.Constants); err != nil {
+		return nil, err
+	}
+
+	var edits api.EditsResponse
+	if err := readFixture(dir, "edits_response.json", &edits); err != nil {
+		return nil, err
+	}
+	var editsBySlug api.EditsBySlugResponse
+	if err := readFixture(dir, "edits_by_slug_response.json", &editsBySlug); err != nil {
+		return nil, err
+	}
+
+	// Merge both listings, keeping the first occurrence of each ID
+	seen := make(map[string]bool)
+	for _, edit := range append(edits.EditRequests, editsBySlug.EditRequests...) {
+		if seen[edit.ID] {
+			continue
+		}
+		seen[edit.ID] = true
+		f.EditRequests = append(f.EditRequests, edit)
+	}
+
+	return f, nil
+}
+
+// readFixture decodes the JSON fixture name in dir into v
+func readFixture(dir, name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to read fixture %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse fixture %s: %w", name, err)
+	}
+	return nil
+}
@@ -1,9 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"net/http"
 	"strconv"
 	"strings"
@@ -14,20 +14,29 @@ import (
 
 // Client wraps the HTTP client for Grokipedia API
 type Client struct {
-	httpClient    *resty.Client
-	baseURL       string
-	timeout       time.Duration
-	verbose       bool
-	debug         bool
-	maxRetryDelay time.Duration
+	httpClient *resty.Client
+	baseURL    string
+	timeout    time.Duration
+	verbose    bool
+	debug      bool
+	retry      RetryPolicy
+	limiter    limiter // nil when client-side rate limiting is off
+
+	conditional *conditionalState // set on clients returned by Conditional
 }
 
 // ClientOptions contains configuration options for the client
 type ClientOptions struct {
-	BaseURL       string
-	Timeout       int // seconds
-	Verbose       bool
-	Debug         bool
+	BaseURL string
+	Timeout int // seconds
+	Verbose bool
+	Debug   bool
+	Retry   RetryPolicy
+
+	// RateLimit paces requests client-side; disabled when zero
+	RateLimit RateLimit
+
+	// MaxRetryDelay caps back-off delays when Retry.MaxDelay is unset
 	MaxRetryDelay time.Duration
 }
 
@@ -52,70 +61,99 @@ func NewClient(opts ClientOptions) *Client {
 		client.SetDebug(true)
 	}
 
+	retry := opts.Retry
+	if retry.MaxDelay <= 0 {
+		retry.MaxDelay = opts.MaxRetryDelay
+	}
+
 	return &Client{
-		httpClient:    client,
-		baseURL:       baseURL,
-		timeout:       timeout,
-		verbose:       opts.Verbose,
-		debug:         opts.Debug,
-		maxRetryDelay: opts.MaxRetryDelay,
+		httpClient: client,
+		baseURL:    baseURL,
+		timeout:    timeout,
+		verbose:    opts.Verbose,
+		debug:      opts.Debug,
+		retry:      retry.withDefaults(),
+		limiter:    newLimiter(opts.RateLimit),
 	}
 }
 
-// doRequest performs an HTTP request with retry logic
-func (c *Client) doRequest(req *resty.Request, endpoint string) (*resty.Response, error) {
-	maxRetries := 3
+// doRequest performs an HTTP request, retrying according to the client's
+// RetryPolicy. Every attempt first waits for the client-side rate limiter,
+// if enabled. The request is bound to ctx, and cancelling ctx aborts both
+// the in-flight call and any back-off or rate-limit wait.
+func (c *Client) doRequest(ctx context.Context, req *resty.Request, endpoint string) (*resty.Response, error) {
+	maxRetries := c.retry.MaxAttempts
 	var lastErr error
 
+	req.SetContext(ctx)
+	c.conditional.setHeaders(req)
+
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
 		resp, err := req.Execute(req.Method, endpoint)
 
 		if err != nil {
-			lastErr = &NetworkError{Message: err.Error()}
+			// A cancelled or expired context is not a network failure
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+
+			lastErr = &NetworkError{Message: err.Error(), Err: err}
 
 			// Check if we should retry
 			if attempt < maxRetries-1 && c.shouldRetry(err) {
-				sleepDuration := c.calculateBackoff(attempt)
-				time.Sleep(sleepDuration)
+				if err := sleep(ctx, c.calculateBackoff(attempt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 
 			return nil, lastErr
 		}
 
-		// Check status code
-		switch resp.StatusCode() {
-		case http.StatusOK:
+		status := resp.StatusCode()
+		switch {
+		case status == http.StatusOK:
+			c.conditional.record(resp)
 			return resp, nil
-		case http.StatusNotFound:
+		case status == http.StatusNotModified:
+			return nil, ErrNotModified
+		case status == http.StatusNotFound:
 			return nil, &NotFoundError{Resource: endpoint}
-		case http.StatusTooManyRequests:
+		case status == http.StatusTooManyRequests:
 			retryAfter := c.parseRetryAfter(resp)
 
-			if attempt < maxRetries-1 {
+			if attempt < maxRetries-1 && c.retry.retryableStatus(status) {
 				sleepDuration := time.Duration(retryAfter) * time.Second
 				if sleepDuration <= 0 {
 					sleepDuration = c.calculateBackoff(attempt)
 				}
-				if c.maxRetryDelay > 0 && sleepDuration > c.maxRetryDelay {
-					sleepDuration = c.maxRetryDelay
+				if sleepDuration > c.retry.MaxDelay {
+					sleepDuration = c.retry.MaxDelay
+				}
+				if err := sleep(ctx, sleepDuration); err != nil {
+					return nil, err
 				}
-				time.Sleep(sleepDuration)
 				continue
 			}
 
 			return nil, &RateLimitError{RetryAfter: retryAfter}
-		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
-			if attempt < maxRetries-1 {
-				sleepDuration := c.calculateBackoff(attempt)
-				time.Sleep(sleepDuration)
+		case status >= http.StatusInternalServerError:
+			if attempt < maxRetries-1 && c.retry.retryableStatus(status) {
+				if err := sleep(ctx, c.calculateBackoff(attempt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
-			return nil, fmt.Errorf("server error: %d", resp.StatusCode())
+			return nil, &ServerError{StatusCode: status}
+		case status >= http.StatusBadRequest:
+			return nil, fmt.Errorf("API error: %d - %s", status, string(resp.Body()))
 		default:
-			if resp.StatusCode() >= 400 {
-				return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode(), string(resp.Body()))
-			}
 			return resp, nil
 		}
 	}
@@ -123,27 +161,31 @@ func (c *Client) doRequest(req *resty.Request, endpoint string) (*resty.Response
 	return nil, lastErr
 }
 
-// shouldRetry determines if a request should be retried
-func (c *Client) shouldRetry(err error) bool {
-	if err == nil {
-		return false
+// sleep waits for d or until ctx is done, whichever comes first
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
 	}
-	errStr := err.Error()
-	return strings.Contains(errStr, "timeout") ||
-		strings.Contains(errStr, "connection refused") ||
-		strings.Contains(errStr, "no such host") ||
-		strings.Contains(errStr, "temporary")
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// shouldRetry determines if a failed request should be retried
+func (c *Client) shouldRetry(err error) bool {
+	return c.retry.retryableError(err)
 }
 
 // calculateBackoff calculates exponential backoff with jitter
 func (c *Client) calculateBackoff(attempt int) time.Duration {
-	if attempt == 0 {
-		return 0
-	}
-
-	baseDelay := time.Duration(1<<(attempt-1)) * time.Second
-	jitter := time.Duration(rand.Intn(1000)) * time.Millisecond
-	return baseDelay + jitter
+	return c.retry.backoff(attempt)
 }
 
 // parseRetryAfter parses the Retry-After header
@@ -163,12 +205,17 @@ func (c *Client) parseRetryAfter(resp *resty.Response) int {
 
 // Search performs a full-text search
 func (c *Client) Search(query string, limit, offset int) (*SearchResponse, error) {
+	return c.SearchContext(context.Background(), query, limit, offset)
+}
+
+// SearchContext is like Search but aborts when ctx is cancelled or its deadline passes
+func (c *Client) SearchContext(ctx context.Context, query string, limit, offset int) (*SearchResponse, error) {
 	req := c.httpClient.R().
 		SetQueryParam("q", query).
 		SetQueryParam("limit", strconv.Itoa(limit)).
 		SetQueryParam("offset", strconv.Itoa(offset))
 
-	resp, err := c.doRequest(req, "/api/full-text-search")
+	resp, err := c.doRequest(ctx, req, EndpointSearch)
 	if err != nil {
 		return nil, err
 	}
@@ -183,12 +230,17 @@ func (c *Client) Search(query string, limit, offset int) (*SearchResponse, error
 
 // Page retrieves a page by slug
 func (c *Client) Page(slug string, includeContent, validateLinks bool) (*PageResponse, error) {
+	return c.PageContext(context.Background(), slug, includeContent, validateLinks)
+}
+
+// PageContext is like Page but aborts when ctx is cancelled or its deadline passes
+func (c *Client) PageContext(ctx context.Context, slug string, includeContent, validateLinks bool) (*PageResponse, error) {
 	req := c.httpClient.R().
 		SetQueryParam("slug", slug).
 		SetQueryParam("includeContent", strconv.FormatBool(includeContent)).
 		SetQueryParam("validateLinks", strconv.FormatBool(validateLinks))
 
-	resp, err := c.doRequest(req, "/api/page")
+	resp, err := c.doRequest(ctx, req, EndpointPage)
 	if err != nil {
 		return nil, err
 	}
@@ -203,11 +255,16 @@ func (c *Client) Page(slug string, includeContent, validateLinks bool) (*PageRes
 
 // Typeahead retrieves search suggestions
 func (c *Client) Typeahead(query string, limit int) (*TypeaheadResponse, error) {
+	return c.TypeaheadContext(context.Background(), query, limit)
+}
+
+// TypeaheadContext is like Typeahead but aborts when ctx is cancelled or its deadline passes
+func (c *Client) TypeaheadContext(ctx context.Context, query string, limit int) (*TypeaheadResponse, error) {
 	req := c.httpClient.R().
 		SetQueryParam("q", query).
 		SetQueryParam("limit", strconv.Itoa(limit))
 
-	resp, err := c.doRequest(req, "/api/typeahead")
+	resp, err := c.doRequest(ctx, req, EndpointTypeahead)
 	if err != nil {
 		return nil, err
 	}
@@ -222,9 +279,14 @@ func (c *Client) Typeahead(query string, limit int) (*TypeaheadResponse, error)
 
 // Constants retrieves API constants
 func (c *Client) Constants() (ConstantsResponse, error) {
+	return c.ConstantsContext(context.Background())
+}
+
+// ConstantsContext is like Constants but aborts when ctx is cancelled or its deadline passes
+func (c *Client) ConstantsContext(ctx context.Context) (ConstantsResponse, error) {
 	req := c.httpClient.R()
 
-	resp, err := c.doRequest(req, "/api/constants")
+	resp, err := c.doRequest(ctx, req, EndpointConstants)
 	if err != nil {
 		return nil, err
 	}
@@ -239,10 +301,30 @@ func (c *Client) Constants() (ConstantsResponse, error) {
 
 // Edits retrieves edit requests
 func (c *Client) Edits(limit int, status []string, excludeUsers []string, includeCounts bool) (*EditsResponse, error) {
+	return c.EditsContext(context.Background(), limit, status, excludeUsers, includeCounts)
+}
+
+// EditsContext is like Edits but aborts when ctx is cancelled or its deadline passes
+func (c *Client) EditsContext(ctx context.Context, limit int, status []string, excludeUsers []string, includeCounts bool) (*EditsResponse, error) {
+	return c.EditsPageContext(ctx, limit, 0, status, excludeUsers, includeCounts)
+}
+
+// EditsPageContext is like EditsContext but starts the listing at offset, to
+// continue a listing that reported HasMore.
+//
+// The list-edit-requests endpoint documents no offset parameter. It is sent
+// as list-edit-requests-by-slug takes it, and only when non-zero, so that
+// first pages are requested exactly as before. EditsAll detects a server
+// that ignores it.
+func (c *Client) EditsPageContext(ctx context.Context, limit, offset int, status []string, excludeUsers []string, includeCounts bool) (*EditsResponse, error) {
 	req := c.httpClient.R().
 		SetQueryParam("limit", strconv.Itoa(limit)).
 		SetQueryParam("includeCounts", strconv.FormatBool(includeCounts))
 
+	if offset > 0 {
+		req.SetQueryParam("offset", strconv.Itoa(offset))
+	}
+
 	for _, s := range status {
 		req.SetQueryParam("status[]", "EDIT_REQUEST_STATUS_"+strings.ToUpper(s))
 	}
@@ -251,7 +333,7 @@ func (c *Client) Edits(limit int, status []string, excludeUsers []string, includ
 		req.SetQueryParam("excludeUserId[]", user)
 	}
 
-	resp, err := c.doRequest(req, "/api/list-edit-requests")
+	resp, err := c.doRequest(ctx, req, EndpointEdits)
 	if err != nil {
 		return nil, err
 	}
@@ -266,12 +348,17 @@ func (c *Client) Edits(limit int, status []string, excludeUsers []string, includ
 
 // EditsBySlug retrieves edit requests for a specific slug
 func (c *Client) EditsBySlug(slug string, limit, offset int) (*EditsBySlugResponse, error) {
+	return c.EditsBySlugContext(context.Background(), slug, limit, offset)
+}
+
+// EditsBySlugContext is like EditsBySlug but aborts when ctx is cancelled or its deadline passes
+func (c *Client) EditsBySlugContext(ctx context.Context, slug string, limit, offset int) (*EditsBySlugResponse, error) {
 	req := c.httpClient.R().
 		SetQueryParam("slug", slug).
 		SetQueryParam("limit", strconv.Itoa(limit)).
 		SetQueryParam("offset", strconv.Itoa(offset))
 
-	resp, err := c.doRequest(req, "/api/list-edit-requests-by-slug")
+	resp, err := c.doRequest(ctx, req, EndpointEditsBySlug)
 	if err != nil {
 		return nil, err
 	}
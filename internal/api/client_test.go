@@ -1,10 +1,17 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -382,22 +389,27 @@ func TestShouldRetry(t *testing.T) {
 	}{
 		{
 			name:     "timeout error",
-			err:      &NetworkError{Message: "connection timeout"},
+			err:      &url.Error{Op: "Get", URL: "http://example.com", Err: timeoutError{}},
 			expected: true,
 		},
 		{
 			name:     "connection refused",
-			err:      &NetworkError{Message: "connection refused"},
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			expected: true,
+		},
+		{
+			name:     "connection reset",
+			err:      &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
 			expected: true,
 		},
 		{
 			name:     "no such host",
-			err:      &NetworkError{Message: "no such host"},
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}},
 			expected: true,
 		},
 		{
-			name:     "temporary error",
-			err:      &NetworkError{Message: "temporary failure"},
+			name:     "unexpected EOF",
+			err:      &url.Error{Op: "Get", URL: "http://example.com", Err: io.ErrUnexpectedEOF},
 			expected: true,
 		},
 		{
@@ -407,7 +419,12 @@ func TestShouldRetry(t *testing.T) {
 		},
 		{
 			name:     "other error",
-			err:      &NetworkError{Message: "some other error"},
+			err:      errors.New("some other error"),
+			expected: false,
+		},
+		{
+			name:     "error text alone is not enough",
+			err:      errors.New("temporary timeout"),
 			expected: false,
 		},
 	}
@@ -421,3 +438,205 @@ func TestShouldRetry(t *testing.T) {
 		})
 	}
 }
+
+// timeoutError is a net.Error reporting a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryPolicyCustomErrors(t *testing.T) {
+	errFlaky := errors.New("flaky transport")
+	client := NewClient(ClientOptions{
+		Retry: RetryPolicy{RetryableErrors: []error{errFlaky}},
+	})
+
+	if !client.shouldRetry(fmt.Errorf("wrapped: %w", errFlaky)) {
+		t.Error("Expected custom retryable error to be retried")
+	}
+	if client.shouldRetry(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}) {
+		t.Error("Expected default retryable errors to be replaced by the custom list")
+	}
+}
+
+func TestParseRetryableErrors(t *testing.T) {
+	errs, err := ParseRetryableErrors([]string{"reset", "timeout"})
+	if err != nil {
+		t.Fatalf("ParseRetryableErrors() error = %v", err)
+	}
+	client := NewClient(ClientOptions{Retry: RetryPolicy{RetryableErrors: errs}})
+
+	if !client.shouldRetry(&net.OpError{Op: "read", Err: syscall.ECONNRESET}) {
+		t.Error("Expected connection resets to be retried")
+	}
+	if !client.shouldRetry(timeoutError{}) {
+		t.Error("Expected timeouts to be retried")
+	}
+	if client.shouldRetry(&net.DNSError{Err: "no such host", Name: "example.invalid"}) {
+		t.Error("Expected DNS failures not to be retried when left out")
+	}
+
+	errs, err = ParseRetryableErrors([]string{"none"})
+	if err != nil || errs == nil || len(errs) != 0 {
+		t.Errorf("Expected none to give an empty list, got %v (error %v)", errs, err)
+	}
+	if errs, _ := ParseRetryableErrors(nil); errs != nil {
+		t.Errorf("Expected no names to keep the defaults, got %v", errs)
+	}
+	if _, err := ParseRetryableErrors([]string{"flaky"}); err == nil {
+		t.Error("Expected an error for an unknown name")
+	}
+}
+
+func TestRetryPolicyAttempts(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		retry        RetryPolicy
+		wantRequests int
+	}{
+		{
+			name:         "default retries 500",
+			status:       http.StatusInternalServerError,
+			wantRequests: 3,
+		},
+		{
+			name:         "default retries 504",
+			status:       http.StatusGatewayTimeout,
+			wantRequests: 3,
+		},
+		{
+			name:         "single attempt disables retries",
+			status:       http.StatusServiceUnavailable,
+			retry:        RetryPolicy{MaxAttempts: 1},
+			wantRequests: 1,
+		},
+		{
+			name:         "status not in custom list",
+			status:       http.StatusBadGateway,
+			retry:        RetryPolicy{RetryableStatuses: []int{http.StatusServiceUnavailable}},
+			wantRequests: 1,
+		},
+		{
+			name:         "more attempts",
+			status:       http.StatusBadGateway,
+			retry:        RetryPolicy{MaxAttempts: 5},
+			wantRequests: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			retry := tt.retry
+			retry.BaseDelay = time.Millisecond
+			client := NewClient(ClientOptions{
+				BaseURL: server.URL,
+				Timeout: 30,
+				Retry:   retry,
+			})
+
+			_, err := client.Search("test", 10, 0)
+			var serverErr *ServerError
+			if !errors.As(err, &serverErr) || serverErr.StatusCode != tt.status {
+				t.Errorf("Expected ServerError %d, got %v", tt.status, err)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tt.wantRequests, requests)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyRateLimitWithoutRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOptions{
+		BaseURL: server.URL,
+		Timeout: 30,
+		Retry:   RetryPolicy{MaxAttempts: 1},
+	})
+
+	_, err := client.Search("test", 10, 0)
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter != 60 {
+		t.Errorf("Expected RateLimitError with RetryAfter 60, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected a single request, got %d", requests)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 250 * time.Millisecond}.withDefaults()
+
+	if d := policy.backoff(0); d != 0 {
+		t.Errorf("backoff(0) = %v, want 0", d)
+	}
+	if d := policy.backoff(1); d < 100*time.Millisecond || d >= 200*time.Millisecond {
+		t.Errorf("backoff(1) = %v, want within [100ms, 200ms)", d)
+	}
+	for attempt := 2; attempt < 70; attempt++ {
+		if d := policy.backoff(attempt); d > 250*time.Millisecond || d <= 0 {
+			t.Errorf("backoff(%d) = %v, want capped at 250ms", attempt, d)
+		}
+	}
+}
+
+func TestClientContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not reach the server with a cancelled context")
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOptions{
+		BaseURL: server.URL,
+		Timeout: 30,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.SearchContext(ctx, "test", 10, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestClientContextAbortsBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOptions{
+		BaseURL: server.URL,
+		Timeout: 30,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.PageContext(ctx, "Test_page", false, false)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Back-off was not interrupted, took %v", elapsed)
+	}
+}
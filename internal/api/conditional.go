@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ErrNotModified is returned by a conditional request when the server
+// answers 304 Not Modified, confirming that the caller's copy is current
+var ErrNotModified = errors.New("not modified")
+
+// Validators are the HTTP cache validators of a response, used to
+// revalidate a stored copy with a conditional request
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// IsZero reports whether no validator is set
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// Conditional is implemented by clients that can revalidate a stored
+// response instead of downloading it again
+type Conditional interface {
+	// Conditional returns a client whose requests are conditional on v
+	Conditional(v Validators) ConditionalClient
+}
+
+// ConditionalClient is a client returned by Conditional. A request whose
+// resource has not changed fails with ErrNotModified instead of returning
+// a body.
+type ConditionalClient interface {
+	GrokipediaAPI
+
+	// Validators returns the validators of the response received. They are
+	// cleared when several responses were received, since a result
+	// assembled from many responses cannot be revalidated as a whole.
+	Validators() Validators
+}
+
+var _ Conditional = (*Client)(nil)
+
+// Conditional implements Conditional. The returned client shares c's HTTP
+// client, retry policy and rate limiter. Zero validators leave its requests
+// unconditional, but it still records the validators it receives.
+func (c *Client) Conditional(v Validators) ConditionalClient {
+	conditional := *c
+	conditional.conditional = &conditionalState{sent: v}
+	return &conditionalClient{Client: &conditional}
+}
+
+// conditionalClient is the ConditionalClient returned by Client.Conditional
+type conditionalClient struct {
+	*Client
+}
+
+// Validators implements ConditionalClient
+func (c *conditionalClient) Validators() Validators {
+	return c.conditional.validators()
+}
+
+// conditionalState holds the validators a conditional client sends and
+// those it has received. Requests may be sent concurrently, so access to
+// what was received is locked.
+type conditionalState struct {
+	sent Validators
+
+	mu        sync.Mutex
+	received  Validators
+	responses int
+}
+
+// setHeaders adds the If-None-Match and If-Modified-Since headers of the
+// validators sent to req. A nil state sends none.
+func (s *conditionalState) setHeaders(req *resty.Request) {
+	if s == nil {
+		return
+	}
+	if s.sent.ETag != "" {
+		req.SetHeader("If-None-Match", s.sent.ETag)
+	}
+	if s.sent.LastModified != "" {
+		req.SetHeader("If-Modified-Since", s.sent.LastModified)
+	}
+}
+
+// record notes the validators of a successful response. A nil state
+// records nothing.
+func (s *conditionalState) record(resp *resty.Response) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses++
+	if s.responses > 1 {
+		s.received = Validators{}
+		return
+	}
+	s.received = Validators{
+		ETag:         resp.Header().Get("ETag"),
+		LastModified: resp.Header().Get("Last-Modified"),
+	}
+}
+
+// validators returns the validators received
+func (s *conditionalState) validators() Validators {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.received
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestConditionalRequestNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != `"v1"` {
+			t.Errorf("Expected If-None-Match \"v1\", got %q", r.Header.Get("If-None-Match"))
+		}
+		if r.Header.Get("If-Modified-Since") != "Mon, 02 Jan 2006 15:04:05 GMT" {
+			t.Errorf("Expected If-Modified-Since header, got %q", r.Header.Get("If-Modified-Since"))
+		}
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOptions{BaseURL: server.URL, Timeout: 30})
+
+	conditional := client.Conditional(Validators{
+		ETag:         `"v1"`,
+		LastModified: "Mon, 02 Jan 2006 15:04:05 GMT",
+	})
+	_, err := conditional.PageContext(context.Background(), "Python", false, true)
+	if !errors.Is(err, ErrNotModified) {
+		t.Errorf("Expected ErrNotModified, got %v", err)
+	}
+}
+
+func TestUnconditionalRequestHasNoValidators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			t.Error("Expected no conditional headers")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results": [], "totalCount": 0}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOptions{BaseURL: server.URL, Timeout: 30})
+
+	if _, err := client.SearchContext(context.Background(), "test", 10, 0); err != nil {
+		t.Fatalf("SearchContext() error = %v", err)
+	}
+
+	// Zero validators leave the request unconditional
+	if _, err := client.Conditional(Validators{}).SearchContext(context.Background(), "test", 10, 0); err != nil {
+		t.Fatalf("SearchContext() error = %v", err)
+	}
+}
+
+func TestConditionalValidators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("Last-Modified", "Tue, 03 Jan 2006 15:04:05 GMT")
+		_, _ = w.Write([]byte(`{"results": [], "totalCount": 0}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOptions{BaseURL: server.URL, Timeout: 30})
+
+	conditional := client.Conditional(Validators{})
+	if _, err := conditional.SearchContext(context.Background(), "test", 10, 0); err != nil {
+		t.Fatalf("SearchContext() error = %v", err)
+	}
+	if v := conditional.Validators(); v.ETag != `"abc"` || v.LastModified != "Tue, 03 Jan 2006 15:04:05 GMT" {
+		t.Errorf("Recorded validators = %+v", v)
+	}
+
+	// A second response on the same client invalidates the validators
+	if _, err := conditional.SearchContext(context.Background(), "test", 10, 10); err != nil {
+		t.Fatalf("SearchContext() error = %v", err)
+	}
+	if v := conditional.Validators(); !v.IsZero() {
+		t.Errorf("Expected validators cleared after several responses, got %+v", v)
+	}
+}
+
+func TestConditionalConcurrentRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"abc"`)
+		_, _ = w.Write([]byte(`{"results": [], "totalCount": 0}`))
+	}))
+	defer server.Close()
+
+	conditional := NewClient(ClientOptions{BaseURL: server.URL, Timeout: 30}).Conditional(Validators{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := conditional.SearchContext(context.Background(), "test", 10, 0); err != nil {
+				t.Errorf("SearchContext() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v := conditional.Validators(); !v.IsZero() {
+		t.Errorf("Expected validators cleared after concurrent responses, got %+v", v)
+	}
+}
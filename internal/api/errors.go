@@ -12,6 +12,7 @@ const (
 	ExitNotFound     = 2
 	ExitRateLimited  = 3
 	ExitInvalidArgs  = 4
+	ExitNotCached    = 5
 )
 
 // APIError is the base error type for API errors
@@ -69,16 +70,48 @@ func (e *InvalidArgsError) ExitCode() int {
 // NetworkError represents network/timeout errors
 type NetworkError struct {
 	Message string
+	Err     error // underlying transport error, if any
 }
 
 func (e *NetworkError) Error() string {
 	return fmt.Sprintf("Network error: %s", e.Message)
 }
 
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
 func (e *NetworkError) ExitCode() int {
 	return ExitGenericError
 }
 
+// ServerError represents a 5xx response that was not resolved by retrying
+type ServerError struct {
+	StatusCode int
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server error: %d", e.StatusCode)
+}
+
+func (e *ServerError) ExitCode() int {
+	return ExitGenericError
+}
+
+// NotCachedError represents a request that could not be answered in
+// offline mode because no cached response exists for it
+type NotCachedError struct {
+	Request string
+}
+
+func (e *NotCachedError) Error() string {
+	return fmt.Sprintf("Not cached (offline): %s", e.Request)
+}
+
+func (e *NotCachedError) ExitCode() int {
+	return ExitNotCached
+}
+
 // UnknownConstantError represents an unknown constant key
 type UnknownConstantError struct {
 	Key string
@@ -92,6 +125,16 @@ func (e *UnknownConstantError) ExitCode() int {
 	return ExitNotFound
 }
 
+// IsUnavailable reports whether err means the API could not serve the
+// request right now (network failure, rate limiting or a 5xx response), as
+// opposed to rejecting it
+func IsUnavailable(err error) bool {
+	var networkErr *NetworkError
+	var rateLimitErr *RateLimitError
+	var serverErr *ServerError
+	return errors.As(err, &networkErr) || errors.As(err, &rateLimitErr) || errors.As(err, &serverErr)
+}
+
 // GetExitCode returns the exit code for an error
 func GetExitCode(err error) int {
 	if err == nil {
@@ -2,6 +2,8 @@ package api
 
 import (
 	"errors"
+	"fmt"
+	"syscall"
 	"testing"
 )
 
@@ -77,6 +79,40 @@ func TestNetworkError(t *testing.T) {
 	}
 }
 
+func TestNetworkErrorUnwrap(t *testing.T) {
+	err := &NetworkError{Message: "connect: connection refused", Err: syscall.ECONNREFUSED}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Error("Expected NetworkError to unwrap to the transport error")
+	}
+}
+
+func TestServerError(t *testing.T) {
+	err := &ServerError{StatusCode: 503}
+
+	expectedMsg := "server error: 503"
+	if err.Error() != expectedMsg {
+		t.Errorf("Expected error message %q, got %q", expectedMsg, err.Error())
+	}
+
+	if err.ExitCode() != ExitGenericError {
+		t.Errorf("Expected exit code %d, got %d", ExitGenericError, err.ExitCode())
+	}
+}
+
+func TestNotCachedError(t *testing.T) {
+	err := &NotCachedError{Request: "/api/page?slug=Go"}
+
+	expectedMsg := "Not cached (offline): /api/page?slug=Go"
+	if err.Error() != expectedMsg {
+		t.Errorf("Expected error message %q, got %q", expectedMsg, err.Error())
+	}
+
+	if err.ExitCode() != ExitNotCached {
+		t.Errorf("Expected exit code %d, got %d", ExitNotCached, err.ExitCode())
+	}
+}
+
 func TestUnknownConstantError(t *testing.T) {
 	err := &UnknownConstantError{Key: "unknown_key"}
 
@@ -126,6 +162,11 @@ func TestGetExitCode(t *testing.T) {
 			err:      &UnknownConstantError{},
 			expected: ExitNotFound,
 		},
+		{
+			name:     "not cached error",
+			err:      &NotCachedError{},
+			expected: ExitNotCached,
+		},
 		{
 			name:     "generic error",
 			err:      errors.New("generic error"),
@@ -160,4 +201,31 @@ func TestExitCodes(t *testing.T) {
 	if ExitInvalidArgs != 4 {
 		t.Errorf("ExitInvalidArgs should be 4, got %d", ExitInvalidArgs)
 	}
+	if ExitNotCached != 5 {
+		t.Errorf("ExitNotCached should be 5, got %d", ExitNotCached)
+	}
+}
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"network error", &NetworkError{Message: "connection refused"}, true},
+		{"rate limited", &RateLimitError{RetryAfter: 5}, true},
+		{"server error", &ServerError{StatusCode: 502}, true},
+		{"wrapped server error", fmt.Errorf("fetch: %w", &ServerError{StatusCode: 500}), true},
+		{"not found", &NotFoundError{Resource: "x"}, false},
+		{"invalid args", &InvalidArgsError{Message: "bad"}, false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUnavailable(tt.err); got != tt.want {
+				t.Errorf("IsUnavailable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
 }
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// DefaultPageSize is the number of items requested per call by the *All
+// iterators when PageOptions.PageSize is not set. It matches the largest
+// limit accepted by the listing endpoints.
+const DefaultPageSize = 100
+
+// PageOptions controls how the *All iterators walk a paginated endpoint
+type PageOptions struct {
+	PageSize int // items requested per call; defaults to DefaultPageSize
+	Offset   int // offset of the first item to return
+	MaxItems int // stop after this many items; 0 means no limit
+}
+
+func (o PageOptions) pageSize() int {
+	if o.PageSize <= 0 {
+		return DefaultPageSize
+	}
+	return o.PageSize
+}
+
+// SearchAll returns an iterator over every search result for query, fetching
+// further pages until TotalCount is reached or opts.MaxItems results have
+// been yielded. A request error is yielded once and ends the iteration.
+func SearchAll(ctx context.Context, c GrokipediaAPI, query string, opts PageOptions) iter.Seq2[SearchResult, error] {
+	return paginate(opts, func(offset int) ([]SearchResult, bool, error) {
+		resp, err := c.SearchContext(ctx, query, opts.pageSize(), offset)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Results, offset+len(resp.Results) < resp.TotalCount, nil
+	})
+}
+
+// EditsAll returns an iterator over every edit request matching the filters,
+// following HasMore until the listing is exhausted or opts.MaxItems edit
+// requests have been yielded. Further pages are requested with
+// EditsPageContext; if the server answers one with the page before it, the
+// offset was ignored and the iteration ends with an error rather than
+// repeating that page.
+func EditsAll(ctx context.Context, c GrokipediaAPI, status []string, excludeUsers []string, opts PageOptions) iter.Seq2[EditRequest, error] {
+	var previous string // ID of the first edit request of the previous page
+	return paginate(opts, func(offset int) ([]EditRequest, bool, error) {
+		resp, err := c.EditsPageContext(ctx, opts.pageSize(), offset, status, excludeUsers, true)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(resp.EditRequests) > 0 {
+			if resp.EditRequests[0].ID == previous {
+				return nil, false, fmt.Errorf("the edits listing ignored offset %d, so it cannot be continued past %d edit requests", offset, offset)
+			}
+			previous = resp.EditRequests[0].ID
+		}
+		return resp.EditRequests, resp.HasMore || offset+len(resp.EditRequests) < resp.TotalCount, nil
+	})
+}
+
+// EditsBySlugAll returns an iterator over every edit request for slug,
+// following HasMore until the listing is exhausted or opts.MaxItems edit
+// requests have been yielded
+func EditsBySlugAll(ctx context.Context, c GrokipediaAPI, slug string, opts PageOptions) iter.Seq2[EditRequest, error] {
+	return paginate(opts, func(offset int) ([]EditRequest, bool, error) {
+		resp, err := c.EditsBySlugContext(ctx, slug, opts.pageSize(), offset)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.EditRequests, resp.HasMore || offset+len(resp.EditRequests) < resp.TotalCount, nil
+	})
+}
+
+// Collect drains seq into a slice, stopping at the first error
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	items := []T{}
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// paginate turns a page fetcher into an item iterator. fetch returns the
+// items at offset and whether more pages follow.
+func paginate[T any](opts PageOptions, fetch func(offset int) ([]T, bool, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		offset := opts.Offset
+		yielded := 0
+
+		for {
+			items, more, err := fetch(offset)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if opts.MaxItems > 0 && yielded >= opts.MaxItems {
+					return
+				}
+				if !yield(item, nil) {
+					return
+				}
+				yielded++
+			}
+
+			// An empty page means the server has nothing further to give,
+			// whatever the counts claim
+			if !more || len(items) == 0 || (opts.MaxItems > 0 && yielded >= opts.MaxItems) {
+				return
+			}
+			offset += len(items)
+		}
+	}
+}
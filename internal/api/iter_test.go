@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newSearchPagingServer serves total results from /api/full-text-search,
+// honoring limit and offset, and counts the requests it receives
+func newSearchPagingServer(t *testing.T, total int, requests *int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		response := SearchResponse{TotalCount: total}
+		for i := offset; i < offset+limit && i < total; i++ {
+			response.Results = append(response.Results, SearchResult{Slug: fmt.Sprintf("Page_%d", i)})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestSearchAllWalksEveryPage(t *testing.T) {
+	requests := 0
+	server := newSearchPagingServer(t, 7, &requests)
+	defer server.Close()
+
+	client := NewClient(ClientOptions{BaseURL: server.URL, Timeout: 30})
+
+	results, err := Collect(SearchAll(context.Background(), client, "test", PageOptions{PageSize: 3}))
+	if err != nil {
+		t.Fatalf("SearchAll() error = %v", err)
+	}
+
+	if len(results) != 7 {
+		t.Fatalf("Expected 7 results, got %d", len(results))
+	}
+	for i, r := range results {
+		if want := fmt.Sprintf("Page_%d", i); r.Slug != want {
+			t.Errorf("results[%d] = %q, want %q", i, r.Slug, want)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
+
+func TestSearchAllMaxItems(t *testing.T) {
+	requests := 0
+	server := newSearchPagingServer(t, 50, &requests)
+	defer server.Close()
+
+	client := NewClient(ClientOptions{BaseURL: server.URL, Timeout: 30})
+
+	results, err := Collect(SearchAll(context.Background(), client, "test", PageOptions{PageSize: 4, Offset: 2, MaxItems: 5}))
+	if err != nil {
+		t.Fatalf("SearchAll() error = %v", err)
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(results))
+	}
+	if results[0].Slug != "Page_2" {
+		t.Errorf("Expected iteration to start at offset 2, got %q", results[0].Slug)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+func TestSearchAllEarlyBreak(t *testing.T) {
+	requests := 0
+	server := newSearchPagingServer(t, 50, &requests)
+	defer server.Close()
+
+	client := NewClient(ClientOptions{BaseURL: server.URL, Timeout: 30})
+
+	seen := 0
+	for _, err := range SearchAll(context.Background(), client, "test", PageOptions{PageSize: 10}) {
+		if err != nil {
+			t.Fatalf("SearchAll() error = %v", err)
+		}
+		seen++
+		if seen == 3 {
+			break
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected 1 request after breaking early, got %d", requests)
+	}
+}
+
+func TestEditsAllFollowsHasMore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		response := EditsResponse{HasMore: offset == 0}
+		response.EditRequests = []EditRequest{
+			{ID: fmt.Sprintf("req-%d", offset)},
+			{ID: fmt.Sprintf("req-%d", offset+1)},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOptions{BaseURL: server.URL, Timeout: 30})
+
+	edits, err := Collect(EditsAll(context.Background(), client, nil, nil, PageOptions{PageSize: 2}))
+	if err != nil {
+		t.Fatalf("EditsAll() error = %v", err)
+	}
+
+	if len(edits) != 4 || edits[3].ID != "req-3" {
+		t.Errorf("Expected req-0..req-3, got %+v", edits)
+	}
+}
+
+func TestEditsAllIgnoredOffset(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		response := EditsResponse{HasMore: true, EditRequests: []EditRequest{{ID: "req-0"}, {ID: "req-1"}}}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOptions{BaseURL: server.URL, Timeout: 30})
+
+	edits, err := Collect(EditsAll(context.Background(), client, nil, nil, PageOptions{PageSize: 2}))
+	if err == nil {
+		t.Fatal("Expected an error when the offset is ignored")
+	}
+	if len(edits) != 2 || requests != 2 {
+		t.Errorf("Expected the first page only after 2 requests, got %d edits after %d requests", len(edits), requests)
+	}
+}
+
+func TestEditsBySlugAllError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOptions{BaseURL: server.URL, Timeout: 30})
+
+	edits, err := Collect(EditsBySlugAll(context.Background(), client, "Missing", PageOptions{}))
+	var notFoundErr *NotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Errorf("Expected NotFoundError, got %v", err)
+	}
+	if len(edits) != 0 {
+		t.Errorf("Expected no edits, got %d", len(edits))
+	}
+}
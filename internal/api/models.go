@@ -1,5 +1,10 @@
 package api
 
+import (
+	"sort"
+	"strings"
+)
+
 // SearchResponse represents the response from /api/full-text-search
 type SearchResponse struct {
 	Results          []SearchResult `json:"results"`
@@ -78,6 +83,53 @@ type TypeaheadResponse struct {
 // The structure is dynamic, so we use a map
 type ConstantsResponse map[string]interface{}
 
+// EditStatusPrefix prefixes the edit request status values used by the API
+const EditStatusPrefix = "EDIT_REQUEST_STATUS_"
+
+// TrimEditStatus returns an edit request status without EditStatusPrefix,
+// as shown in tables
+func TrimEditStatus(status string) string {
+	return strings.TrimPrefix(status, EditStatusPrefix)
+}
+
+// DefaultEditStatuses are the edit request statuses accepted as filters
+// when the constants do not list them
+var DefaultEditStatuses = []string{"approved", "implemented", "pending"}
+
+// EditStatuses returns the edit request statuses found anywhere in the
+// constants, as the lowercase names accepted by the status filter, sorted.
+// The unspecified status is left out.
+func (c ConstantsResponse) EditStatuses() []string {
+	seen := make(map[string]bool)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case string:
+			name, ok := strings.CutPrefix(v, EditStatusPrefix)
+			if ok && name != "" && name != "UNSPECIFIED" {
+				seen[strings.ToLower(name)] = true
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]interface{}:
+			for key, item := range v {
+				walk(key)
+				walk(item)
+			}
+		}
+	}
+	walk(map[string]interface{}(c))
+
+	statuses := make([]string, 0, len(seen))
+	for name := range seen {
+		statuses = append(statuses, name)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
 // EditsResponse represents the response from /api/list-edit-requests
 type EditsResponse struct {
 	EditRequests         []EditRequest `json:"editRequests"`
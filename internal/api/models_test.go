@@ -165,6 +165,30 @@ func TestConstantsResponseSerialization(t *testing.T) {
 	}
 }
 
+func TestConstantsEditStatuses(t *testing.T) {
+	var constants ConstantsResponse
+	data := `{
+		"editRequestStatus": {
+			"EDIT_REQUEST_STATUS_UNSPECIFIED": 0,
+			"EDIT_REQUEST_STATUS_PENDING": 1
+		},
+		"reviewable": ["EDIT_REQUEST_STATUS_APPROVED", "EDIT_REQUEST_STATUS_PENDING"],
+		"apiVersion": "v1"
+	}`
+	if err := json.Unmarshal([]byte(data), &constants); err != nil {
+		t.Fatal(err)
+	}
+
+	got := constants.EditStatuses()
+	if len(got) != 2 || got[0] != "approved" || got[1] != "pending" {
+		t.Errorf("EditStatuses() = %v, want [approved pending]", got)
+	}
+
+	if got := (ConstantsResponse{"apiVersion": "v1"}).EditStatuses(); len(got) != 0 {
+		t.Errorf("Expected no statuses, got %v", got)
+	}
+}
+
 func TestEditsResponseSerialization(t *testing.T) {
 	response := EditsResponse{
 		EditRequests: []EditRequest{
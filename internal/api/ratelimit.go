@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/grokipedia/cli/internal/filelock"
+)
+
+// RateLimit configures the optional client-side token bucket that paces
+// requests before they are sent. A zero RequestsPerSecond disables it.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained rate at which tokens are refilled
+	RequestsPerSecond float64
+	// Burst is the bucket capacity; values below 1 are treated as 1
+	Burst int
+	// StateFile, when set, persists the bucket so that every process using
+	// the same file shares a single budget. Access is serialized with an
+	// advisory lock on the file. If the file cannot be read or written, the
+	// process falls back to a budget of its own.
+	StateFile string
+	// Warnings, if set, receives a line when the state file fails
+	Warnings io.Writer
+}
+
+// Enabled reports whether the rate limit is active
+func (r RateLimit) Enabled() bool {
+	return r.RequestsPerSecond > 0
+}
+
+// limiter paces outgoing requests
+type limiter interface {
+	// Wait blocks until a request may be sent or ctx is done
+	Wait(ctx context.Context) error
+}
+
+// newLimiter returns the limiter for r, or nil when r is disabled
+func newLimiter(r RateLimit) limiter {
+	if !r.Enabled() {
+		return nil
+	}
+
+	burst := r.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	if r.StateFile != "" {
+		return &fileLimiter{path: r.StateFile, rate: r.RequestsPerSecond, burst: burst, warnings: r.Warnings}
+	}
+	return newMemoryLimiter(r.RequestsPerSecond, burst)
+}
+
+// bucket is the token bucket state, shared on disk by fileLimiter
+type bucket struct {
+	Tokens  float64 `json:"tokens"`
+	Updated int64   `json:"updated"` // unix nanoseconds
+}
+
+// reserve refills the bucket up to now and takes one token from it,
+// returning how long the caller must wait before the token is usable.
+// Tokens may go negative so that waiting callers queue in order.
+func (b *bucket) reserve(now time.Time, rate float64, burst int) time.Duration {
+	elapsed := now.Sub(time.Unix(0, b.Updated)).Seconds()
+	if elapsed > 0 {
+		b.Tokens = math.Min(float64(burst), b.Tokens+elapsed*rate)
+	}
+	b.Updated = now.UnixNano()
+
+	b.Tokens--
+	if b.Tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.Tokens / rate * float64(time.Second))
+}
+
+// memoryLimiter is a token bucket local to this process
+type memoryLimiter struct {
+	rate  float64
+	burst int
+
+	mu     sync.Mutex
+	bucket bucket
+}
+
+// newMemoryLimiter returns a memoryLimiter starting with a full bucket
+func newMemoryLimiter(rate float64, burst int) *memoryLimiter {
+	return &memoryLimiter{
+		rate:   rate,
+		burst:  burst,
+		bucket: bucket{Tokens: float64(burst), Updated: time.Now().UnixNano()},
+	}
+}
+
+func (l *memoryLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	wait := l.bucket.reserve(time.Now(), l.rate, l.burst)
+	l.mu.Unlock()
+
+	return sleep(ctx, wait)
+}
+
+// fileLimiter is a token bucket stored in a locked file so that concurrent
+// CLI processes draw from the same budget. Once the file fails, the rest of
+// the process is paced by a memoryLimiter instead, so that an unwritable
+// cache directory slows nothing down but the sharing.
+type fileLimiter struct {
+	path     string
+	rate     float64
+	burst    int
+	warnings io.Writer
+
+	mu       sync.Mutex
+	fallback *memoryLimiter // set once the state file has failed
+}
+
+func (l *fileLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if fallback := l.fallbackLimiter(nil); fallback != nil {
+		return fallback.Wait(ctx)
+	}
+
+	wait, err := l.reserve()
+	if err != nil {
+		return l.fallbackLimiter(err).Wait(ctx)
+	}
+
+	return sleep(ctx, wait)
+}
+
+// fallbackLimiter returns the in-process limiter used since the state file
+// failed, or nil if it has not. A non-nil err reports a failure, creating
+// the fallback with a warning if this is the first.
+func (l *fileLimiter) fallbackLimiter(err error) *memoryLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.fallback == nil && err != nil {
+		if l.warnings != nil {
+			fmt.Fprintf(l.warnings, "warning: rate limiter: %v; limiting this process only\n", err)
+		}
+		l.fallback = newMemoryLimiter(l.rate, l.burst)
+	}
+	return l.fallback
+}
+
+// reserve takes a token from the shared bucket while holding the file lock
+func (l *fileLimiter) reserve() (time.Duration, error) {
+	var wait time.Duration
+	err := filelock.Update(l.path, func(data []byte) ([]byte, error) {
+		now := time.Now()
+
+		// A missing, empty or corrupt state file starts a full bucket
+		state := bucket{Tokens: float64(l.burst), Updated: now.UnixNano()}
+		if len(data) > 0 {
+			var stored bucket
+			if json.Unmarshal(data, &stored) == nil {
+				state = stored
+			}
+		}
+
+		wait = state.reserve(now, l.rate, l.burst)
+		return json.Marshal(state)
+	})
+	return wait, err
+}
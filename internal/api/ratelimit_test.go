@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBucketReserve(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	b := bucket{Tokens: 2, Updated: start.UnixNano()}
+
+	// Burst of two is available immediately
+	for i := 0; i < 2; i++ {
+		if wait := b.reserve(start, 10, 2); wait != 0 {
+			t.Fatalf("reserve %d: wait = %v, want 0", i, wait)
+		}
+	}
+
+	// The third request queues behind the refill at 10/s
+	if wait := b.reserve(start, 10, 2); wait != 100*time.Millisecond {
+		t.Errorf("wait = %v, want 100ms", wait)
+	}
+	if wait := b.reserve(start, 10, 2); wait != 200*time.Millisecond {
+		t.Errorf("wait = %v, want 200ms", wait)
+	}
+
+	// A long idle period refills no further than the burst
+	later := start.Add(time.Hour)
+	b.reserve(later, 10, 2)
+	if b.Tokens != 1 {
+		t.Errorf("Tokens = %v, want 1 after refilling to burst and reserving", b.Tokens)
+	}
+}
+
+func TestNewLimiter(t *testing.T) {
+	if l := newLimiter(RateLimit{}); l != nil {
+		t.Errorf("Expected nil limiter when disabled, got %T", l)
+	}
+	if _, ok := newLimiter(RateLimit{RequestsPerSecond: 1}).(*memoryLimiter); !ok {
+		t.Error("Expected memoryLimiter without a state file")
+	}
+	if _, ok := newLimiter(RateLimit{RequestsPerSecond: 1, StateFile: "x"}).(*fileLimiter); !ok {
+		t.Error("Expected fileLimiter with a state file")
+	}
+}
+
+func TestFileLimiterSharedBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.lock")
+
+	// Two limiters on the same file stand in for two CLI processes
+	a := newLimiter(RateLimit{RequestsPerSecond: 1, Burst: 2, StateFile: path}).(*fileLimiter)
+	b := newLimiter(RateLimit{RequestsPerSecond: 1, Burst: 2, StateFile: path}).(*fileLimiter)
+
+	for i, l := range []*fileLimiter{a, b} {
+		wait, err := l.reserve()
+		if err != nil {
+			t.Fatalf("reserve %d: %v", i, err)
+		}
+		if wait != 0 {
+			t.Errorf("reserve %d: wait = %v, want 0 within burst", i, wait)
+		}
+	}
+
+	wait, err := a.reserve()
+	if err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if wait < 900*time.Millisecond {
+		t.Errorf("wait = %v, want about 1s once the shared burst is spent", wait)
+	}
+}
+
+func TestFileLimiterFallsBack(t *testing.T) {
+	// A state file under a regular file can never be opened
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings bytes.Buffer
+	l := newLimiter(RateLimit{
+		RequestsPerSecond: 1,
+		Burst:             3,
+		StateFile:         filepath.Join(parent, "ratelimit.lock"),
+		Warnings:          &warnings,
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait %d: %v", i, err)
+		}
+	}
+	if got := strings.Count(warnings.String(), "warning:"); got != 1 {
+		t.Errorf("Expected one warning, got %q", warnings.String())
+	}
+	if fallback := l.(*fileLimiter).fallbackLimiter(nil); fallback == nil || fallback.bucket.Tokens >= 1 {
+		t.Error("Expected later requests to draw from the in-process bucket")
+	}
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	l := newLimiter(RateLimit{RequestsPerSecond: 0.01})
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := l.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Wait did not return promptly after the deadline")
+	}
+}
+
+func TestClientRateLimiterPacesRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results": [], "totalCount": 0}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOptions{
+		BaseURL: server.URL,
+		Timeout: 30,
+		RateLimit: RateLimit{
+			RequestsPerSecond: 20,
+			StateFile:         filepath.Join(t.TempDir(), "ratelimit.lock"),
+		},
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.Search("test", 10, 0); err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+	}
+
+	// The first request uses the burst token; the other two wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected requests to be paced, took %v", elapsed)
+	}
+}
@@ -0,0 +1,359 @@
+// Package render describes the API responses for the text output formats.
+// Each response type has a counterpart here, defined on the same underlying
+// type so that it encodes the same way, which implements
+// formatter.Renderable.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/formatter"
+)
+
+// maxConstantWidth is the width at which constant values are truncated in
+// tables
+const maxConstantWidth = 80
+
+type (
+	// Search renders an api.SearchResponse
+	Search api.SearchResponse
+	// Page renders an api.PageResponse
+	Page api.PageResponse
+	// Edits renders an api.EditsResponse
+	Edits api.EditsResponse
+	// EditsBySlug renders an api.EditsBySlugResponse
+	EditsBySlug api.EditsBySlugResponse
+	// Typeahead renders an api.TypeaheadResponse
+	Typeahead api.TypeaheadResponse
+	// Constants renders an api.ConstantsResponse
+	Constants api.ConstantsResponse
+)
+
+var (
+	_ formatter.Itemized = (*Search)(nil)
+	_ formatter.Itemized = (*Edits)(nil)
+	_ formatter.Itemized = (*EditsBySlug)(nil)
+	_ formatter.Itemized = (*Typeahead)(nil)
+
+	_ formatter.Recorder = (*Page)(nil)
+
+	_ formatter.Renderable = (*Search)(nil)
+	_ formatter.Renderable = (*Page)(nil)
+	_ formatter.Renderable = (*Edits)(nil)
+	_ formatter.Renderable = (*EditsBySlug)(nil)
+	_ formatter.Renderable = (*Typeahead)(nil)
+	_ formatter.Renderable = Constants(nil)
+)
+
+// Of returns the counterpart of an API response, or v itself if it is not
+// one
+func Of(v interface{}) interface{} {
+	switch r := v.(type) {
+	case *api.SearchResponse:
+		return (*Search)(r)
+	case *api.PageResponse:
+		return (*Page)(r)
+	case *api.EditsResponse:
+		return (*Edits)(r)
+	case *api.EditsBySlugResponse:
+		return (*EditsBySlug)(r)
+	case *api.TypeaheadResponse:
+		return (*Typeahead)(r)
+	case api.ConstantsResponse:
+		return Constants(r)
+	}
+	return v
+}
+
+// Table implements formatter.Renderable
+func (r *Search) Table() formatter.Table {
+	t := formatter.Table{
+		Headers: []string{"Title", "Slug", "Score", "Views"},
+		Empty:   "No results found.",
+	}
+	for _, result := range r.Results {
+		t.Rows = append(t.Rows, []string{
+			result.Title,
+			result.Slug,
+			strconv.FormatFloat(result.RelevanceScore, 'f', 2, 64),
+			strconv.Itoa(result.ViewCount),
+		})
+	}
+	return t
+}
+
+// Document implements formatter.Renderable
+func (r *Search) Document() formatter.Document {
+	doc := formatter.Document{Heading: "Search Results", Empty: "No results found."}
+	for _, result := range r.Results {
+		item := formatter.Item{
+			Title:   result.Title,
+			Link:    result.Slug,
+			Details: []string{fmt.Sprintf("Score: %.2f, Views: %d", result.RelevanceScore, result.ViewCount)},
+		}
+		if result.Snippet != "" {
+			item.Details = append(item.Details, result.Snippet)
+		}
+		doc.Items = append(doc.Items, item)
+	}
+	return doc
+}
+
+// List implements formatter.Renderable, listing the result slugs
+func (r *Search) List() []string {
+	slugs := make([]string, 0, len(r.Results))
+	for _, result := range r.Results {
+		slugs = append(slugs, result.Slug)
+	}
+	return slugs
+}
+
+// Items implements formatter.Itemized
+func (r *Search) Items() []interface{} {
+	return itemsOf(r.Results)
+}
+
+// Table implements formatter.Renderable
+func (r *Page) Table() formatter.Table {
+	p := r.Page
+	return formatter.Table{
+		Headers: []string{"Title", "Slug", "Views", "Quality Score"},
+		Rows: [][]string{{
+			p.Title,
+			p.Slug,
+			strconv.Itoa(p.Stats.TotalViews),
+			strconv.FormatFloat(p.Stats.QualityScore, 'f', 2, 64),
+		}},
+	}
+}
+
+// Document implements formatter.Renderable. The page content is the body,
+// so clear it to leave the content out.
+func (r *Page) Document() formatter.Document {
+	p := r.Page
+	doc := formatter.Document{
+		Title:       p.Title,
+		Description: p.Description,
+		Body:        p.Content,
+		Fields: []formatter.Field{
+			{Name: "Slug", Value: p.Slug},
+			{Name: "Views", Value: strconv.Itoa(p.Stats.TotalViews)},
+			{Name: "Quality Score", Value: strconv.FormatFloat(p.Stats.QualityScore, 'f', 2, 64)},
+		},
+	}
+
+	if len(p.Citations) > 0 {
+		citations := formatter.Section{Title: "Citations"}
+		for _, c := range p.Citations {
+			citations.Items = append(citations.Items, formatter.Item{Title: c.Title, Link: c.URL})
+		}
+		doc.Sections = append(doc.Sections, citations)
+	}
+	return doc
+}
+
+// Record implements formatter.Recorder, so that --fields name the fields of
+// the page, such as stats.totalViews
+func (r *Page) Record() interface{} {
+	return r.Page
+}
+
+// List implements formatter.Renderable, listing the page slug
+func (r *Page) List() []string {
+	return []string{r.Page.Slug}
+}
+
+// Table implements formatter.Renderable. The total count follows the table
+// when the response has one.
+func (r *Edits) Table() formatter.Table {
+	t := formatter.Table{
+		Headers: []string{"ID", "Slug", "Status", "Editor", "Timestamp"},
+		Empty:   "No edit requests found.",
+	}
+	for _, edit := range r.EditRequests {
+		t.Rows = append(t.Rows, []string{edit.ID, edit.Slug, api.TrimEditStatus(edit.Status), edit.Editor, editTime(edit.Timestamp)})
+	}
+	if r.TotalCount > 0 {
+		t.Footer = "Total: " + editsTotal(r.TotalCount, r.HasMore)
+	}
+	return t
+}
+
+// Document implements formatter.Renderable
+func (r *Edits) Document() formatter.Document {
+	doc := formatter.Document{Heading: "Edit Requests", Empty: "No edit requests found."}
+	for _, edit := range r.EditRequests {
+		doc.Items = append(doc.Items, editItem(edit, true))
+	}
+	if len(doc.Items) > 0 && r.TotalCount > 0 {
+		doc.Fields = []formatter.Field{{Name: "Total", Value: editsTotal(r.TotalCount, r.HasMore)}}
+	}
+	return doc
+}
+
+// List implements formatter.Renderable, listing the edit request IDs
+func (r *Edits) List() []string {
+	return editIDs(r.EditRequests)
+}
+
+// Items implements formatter.Itemized
+func (r *Edits) Items() []interface{} {
+	return itemsOf(r.EditRequests)
+}
+
+// Table implements formatter.Renderable
+func (r *EditsBySlug) Table() formatter.Table {
+	t := formatter.Table{
+		Headers: []string{"ID", "Status", "Editor", "Timestamp"},
+		Empty:   "No edit requests found for this page.",
+		Footer:  "Total: " + editsTotal(r.TotalCount, r.HasMore),
+	}
+	for _, edit := range r.EditRequests {
+		t.Rows = append(t.Rows, []string{edit.ID, api.TrimEditStatus(edit.Status), edit.Editor, editTime(edit.Timestamp)})
+	}
+	return t
+}
+
+// Document implements formatter.Renderable
+func (r *EditsBySlug) Document() formatter.Document {
+	doc := formatter.Document{Heading: "Edit Requests", Empty: "No edit requests found for this page."}
+	for _, edit := range r.EditRequests {
+		doc.Items = append(doc.Items, editItem(edit, false))
+	}
+	if len(doc.Items) > 0 {
+		doc.Fields = []formatter.Field{{Name: "Total", Value: editsTotal(r.TotalCount, r.HasMore)}}
+	}
+	return doc
+}
+
+// List implements formatter.Renderable, listing the edit request IDs
+func (r *EditsBySlug) List() []string {
+	return editIDs(r.EditRequests)
+}
+
+// Items implements formatter.Itemized
+func (r *EditsBySlug) Items() []interface{} {
+	return itemsOf(r.EditRequests)
+}
+
+// Table implements formatter.Renderable
+func (r *Typeahead) Table() formatter.Table {
+	t := formatter.Table{Headers: []string{"Suggestion"}, Empty: "No suggestions found."}
+	for _, s := range r.Suggestions {
+		t.Rows = append(t.Rows, []string{s})
+	}
+	return t
+}
+
+// Document implements formatter.Renderable
+func (r *Typeahead) Document() formatter.Document {
+	doc := formatter.Document{Heading: "Suggestions", Empty: "No suggestions found."}
+	for _, s := range r.Suggestions {
+		doc.Items = append(doc.Items, formatter.Item{Title: s})
+	}
+	return doc
+}
+
+// List implements formatter.Renderable, listing the suggestions
+func (r *Typeahead) List() []string {
+	return r.Suggestions
+}
+
+// Items implements formatter.Itemized
+func (r *Typeahead) Items() []interface{} {
+	return itemsOf(r.Suggestions)
+}
+
+// Table implements formatter.Renderable. Long values are truncated in the
+// table format.
+func (c Constants) Table() formatter.Table {
+	t := formatter.Table{
+		Headers:  []string{"Key", "Value"},
+		Empty:    "No constants found.",
+		MaxWidth: maxConstantWidth,
+	}
+	for _, k := range c.List() {
+		t.Rows = append(t.Rows, []string{k, constantValue(c[k])})
+	}
+	return t
+}
+
+// Document implements formatter.Renderable
+func (c Constants) Document() formatter.Document {
+	doc := formatter.Document{Heading: "Constants", Empty: "No constants found."}
+	for _, k := range c.List() {
+		doc.Fields = append(doc.Fields, formatter.Field{Name: k, Value: constantValue(c[k])})
+	}
+	return doc
+}
+
+// List implements formatter.Renderable, listing the keys in order
+func (c Constants) List() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// itemsOf returns items as a slice of interface values
+func itemsOf[T any](items []T) []interface{} {
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		values[i] = item
+	}
+	return values
+}
+
+// constantValue formats a constant for display: strings as they are and
+// anything else as JSON
+func constantValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// editItem returns edit as a document item, with its page when showSlug is
+// set
+func editItem(edit api.EditRequest, showSlug bool) formatter.Item {
+	item := formatter.Item{Title: edit.ID}
+	if showSlug {
+		item.Details = append(item.Details, "Page: "+edit.Slug)
+	}
+	item.Details = append(item.Details, fmt.Sprintf("Status: %s, Editor: %s, %s",
+		api.TrimEditStatus(edit.Status), edit.Editor, editTime(edit.Timestamp)))
+	return item
+}
+
+// editIDs returns the IDs of edits
+func editIDs(edits []api.EditRequest) []string {
+	ids := make([]string, 0, len(edits))
+	for _, edit := range edits {
+		ids = append(ids, edit.ID)
+	}
+	return ids
+}
+
+// editsTotal formats the total count of an edit request listing
+func editsTotal(total int, hasMore bool) string {
+	if hasMore {
+		return strconv.Itoa(total) + " (more available)"
+	}
+	return strconv.Itoa(total)
+}
+
+// editTime formats an edit request timestamp for display
+func editTime(timestamp int64) string {
+	return time.Unix(timestamp, 0).Format(formatter.DateLayout)
+}
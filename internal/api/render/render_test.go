@@ -0,0 +1,55 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grokipedia/cli/internal/api"
+)
+
+func TestEditsTableFooter(t *testing.T) {
+	edits := &Edits{
+		EditRequests: []api.EditRequest{{ID: "req-1", Slug: "Go", Status: "EDIT_REQUEST_STATUS_APPROVED", Editor: "alice"}},
+		TotalCount:   3,
+		HasMore:      true,
+	}
+
+	table := edits.Table()
+	if got := table.Rows[0][2]; got != "APPROVED" {
+		t.Errorf("Expected shortened status, got %q", got)
+	}
+	if table.Footer != "Total: 3 (more available)" {
+		t.Errorf("Unexpected footer %q", table.Footer)
+	}
+
+	edits.TotalCount = 0
+	if footer := edits.Table().Footer; footer != "" {
+		t.Errorf("Expected no footer without a total count, got %q", footer)
+	}
+}
+
+func TestConstantsRender(t *testing.T) {
+	constants := Constants{
+		"long":   strings.Repeat("x", 100),
+		"nested": map[string]interface{}{"key": "value"},
+	}
+
+	if got := constants.List(); !reflect.DeepEqual(got, []string{"long", "nested"}) {
+		t.Errorf("List() = %v, want sorted keys", got)
+	}
+
+	table := constants.Table()
+	rows := table.Rows
+	if table.MaxWidth != maxConstantWidth || len(rows[0][1]) != 100 {
+		t.Errorf("Expected long value left for the table format to truncate, got %q", rows[0][1])
+	}
+	if rows[1][1] != `{"key":"value"}` {
+		t.Errorf("Expected nested value as JSON, got %q", rows[1][1])
+	}
+
+	fields := constants.Document().Fields
+	if len(fields[0].Value) != 100 {
+		t.Error("Expected full values in documents")
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// Retry policy defaults used for zero-valued RetryPolicy fields
+const (
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = time.Second
+	DefaultMaxDelay    = 30 * time.Second
+)
+
+// DefaultRetryableStatuses are the HTTP status codes retried by default
+var DefaultRetryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// ErrTimeout and ErrDNS stand for classes of transport errors in
+// RetryPolicy.RetryableErrors: ErrTimeout matches any net.Error reporting a
+// timeout, and ErrDNS any *net.DNSError
+var (
+	ErrTimeout = errors.New("timeout")
+	ErrDNS     = errors.New("DNS failure")
+)
+
+// DefaultRetryableErrors are the transport errors retried by default
+var DefaultRetryableErrors = []error{
+	ErrTimeout,
+	ErrDNS,
+	syscall.ECONNREFUSED,
+	syscall.ECONNRESET,
+	syscall.ECONNABORTED,
+	syscall.EPIPE,
+	io.ErrUnexpectedEOF,
+	io.EOF,
+}
+
+// RetryableErrorNames maps the names of transport error classes accepted by
+// ParseRetryableErrors to the errors they match. Together they cover
+// DefaultRetryableErrors.
+var RetryableErrorNames = map[string][]error{
+	"timeout":     {ErrTimeout},
+	"dns":         {ErrDNS},
+	"refused":     {syscall.ECONNREFUSED},
+	"reset":       {syscall.ECONNRESET},
+	"aborted":     {syscall.ECONNABORTED},
+	"broken-pipe": {syscall.EPIPE},
+	"eof":         {io.ErrUnexpectedEOF, io.EOF},
+}
+
+// ParseRetryableErrors returns the RetryableErrors of a policy from names
+// in RetryableErrorNames, as given in the configuration. No names keep the
+// defaults, and "none" retries no transport errors.
+func ParseRetryableErrors(names []string) ([]error, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	errs := []error{}
+	for _, name := range names {
+		if name == "none" {
+			continue
+		}
+		matched, ok := RetryableErrorNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown retryable error %q", name)
+		}
+		errs = append(errs, matched...)
+	}
+	return errs, nil
+}
+
+// RetryPolicy controls how failed requests are retried.
+// Zero-valued fields fall back to the package defaults; a non-nil empty
+// slice disables retries for that class of failure.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first.
+	// Set it to 1 to disable retries.
+	MaxAttempts int
+	// BaseDelay is the back-off before the second retry; it doubles with
+	// every further attempt and is jittered by up to BaseDelay. The first
+	// retry is sent immediately.
+	BaseDelay time.Duration
+	// MaxDelay caps any single back-off, including server Retry-After hints
+	MaxDelay time.Duration
+	// RetryableStatuses lists the HTTP status codes worth retrying
+	RetryableStatuses []int
+	// RetryableErrors lists transport errors worth retrying, matched with
+	// errors.Is or as the classes ErrTimeout and ErrDNS
+	RetryableErrors []error
+}
+
+// DefaultRetryPolicy returns the policy used when ClientOptions.Retry is unset
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{}.withDefaults()
+}
+
+// withDefaults fills zero-valued fields with the package defaults
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultMaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultMaxDelay
+	}
+	if p.RetryableStatuses == nil {
+		p.RetryableStatuses = DefaultRetryableStatuses
+	}
+	if p.RetryableErrors == nil {
+		p.RetryableErrors = DefaultRetryableErrors
+	}
+	return p
+}
+
+// retryableStatus reports whether status is listed in the policy
+func (p RetryPolicy) retryableStatus(status int) bool {
+	for _, s := range p.RetryableStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// retryableError reports whether a transport error is worth retrying
+func (p RetryPolicy) retryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, target := range p.RetryableErrors {
+		switch target {
+		case ErrTimeout:
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return true
+			}
+		case ErrDNS:
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) {
+				return true
+			}
+		default:
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before retrying after the given zero-based
+// attempt failed
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if attempt == 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		// Shift overflowed or exceeded the cap
+		return p.MaxDelay
+	}
+
+	delay += time.Duration(rand.Int63n(int64(p.BaseDelay)))
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
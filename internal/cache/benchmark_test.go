@@ -1,8 +1,13 @@
 package cache
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
+
+	"github.com/grokipedia/cli/internal/api"
 )
 
 // BenchmarkCacheKeyGeneration measures cache key generation performance
@@ -36,50 +41,128 @@ func BenchmarkCacheKeyGenerationLargeParams(b *testing.B) {
 
 // BenchmarkCacheSet measures cache write performance
 func BenchmarkCacheSet(b *testing.B) {
-	tmpDir := b.TempDir()
-	c := New(tmpDir, 3600)
-	data := []byte(`{"results": [{"title": "Test", "slug": "Test"}], "totalCount": 1}`)
+	benchForEachBackend(b, func(b *testing.B, newCache func(dir string, ttl int) *Cache) {
+		tmpDir := b.TempDir()
+		c := newCache(tmpDir, 3600)
+		data := []byte(`{"results": [{"title": "Test", "slug": "Test"}], "totalCount": 1}`)
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		key := fmt.Sprintf("key-%d", i)
-		if err := c.Set(key, data); err != nil {
-			b.Fatalf("Set failed: %v", err)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			if err := c.Set(key, data); err != nil {
+				b.Fatalf("Set failed: %v", err)
+			}
 		}
-	}
+	})
 }
 
 // BenchmarkCacheGet measures cache read performance
 func BenchmarkCacheGet(b *testing.B) {
-	tmpDir := b.TempDir()
-	c := New(tmpDir, 3600)
-	data := []byte(`{"results": [{"title": "Test", "slug": "Test"}], "totalCount": 1}`)
-
-	// Pre-populate cache
-	for i := 0; i < 100; i++ {
-		key := fmt.Sprintf("key-%d", i)
-		if err := c.Set(key, data); err != nil {
-			b.Fatalf("Set failed: %v", err)
+	benchForEachBackend(b, func(b *testing.B, newCache func(dir string, ttl int) *Cache) {
+		tmpDir := b.TempDir()
+		c := newCache(tmpDir, 3600)
+		data := []byte(`{"results": [{"title": "Test", "slug": "Test"}], "totalCount": 1}`)
+
+		// Pre-populate cache
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			if err := c.Set(key, data); err != nil {
+				b.Fatalf("Set failed: %v", err)
+			}
 		}
-	}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		key := fmt.Sprintf("key-%d", i%100)
-		c.Get(key)
-	}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			key := fmt.Sprintf("key-%d", i%100)
+			c.Get(key)
+		}
+	})
 }
 
 // BenchmarkCacheSetGet measures combined read/write performance
 func BenchmarkCacheSetGet(b *testing.B) {
-	tmpDir := b.TempDir()
-	c := New(tmpDir, 3600)
-	data := []byte(`{"test": "data"}`)
+	benchForEachBackend(b, func(b *testing.B, newCache func(dir string, ttl int) *Cache) {
+		tmpDir := b.TempDir()
+		c := newCache(tmpDir, 3600)
+		data := []byte(`{"test": "data"}`)
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		key := fmt.Sprintf("key-%d", i)
-		_ = c.Set(key, data)
-		c.Get(key)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			_ = c.Set(key, data)
+			c.Get(key)
+		}
+	})
+}
+
+// benchPage returns a page response with content and citations of roughly
+// the size and redundancy of a real article fetched with --content
+func benchPage(b *testing.B) []byte {
+	b.Helper()
+
+	words := strings.Fields(`the language was designed at a company to improve programming
+		productivity in an era of multicore networked machines and large codebases its
+		designers wanted to address criticism of other languages in use while keeping
+		their useful characteristics such as static typing and run-time efficiency`)
+	rng := rand.New(rand.NewSource(1))
+
+	var content strings.Builder
+	for content.Len() < 64*1024 {
+		for i := 0; i < 12; i++ {
+			content.WriteString(words[rng.Intn(len(words))])
+			content.WriteByte(' ')
+		}
+		content.WriteString(fmt.Sprintf("[%d].\n", rng.Intn(200)))
+	}
+
+	page := api.PageResponse{Found: true, Page: api.PageData{
+		Title:   "Go (programming language)",
+		Slug:    "Go_(programming_language)",
+		Content: content.String(),
+	}}
+	for i := 0; i < 200; i++ {
+		page.Page.Citations = append(page.Page.Citations, api.Citation{
+			ID:    fmt.Sprint(i),
+			Title: fmt.Sprintf("Reference %d", i),
+			URL:   fmt.Sprintf("https://example.com/articles/%d", rng.Int63()),
+		})
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+// BenchmarkCompression measures the time to store and read back a large
+// page with each compression algorithm. The stored-% metric is the stored
+// size as a percentage of the response size.
+func BenchmarkCompression(b *testing.B) {
+	data := benchPage(b)
+
+	for _, algorithm := range Compressions {
+		b.Run(algorithm, func(b *testing.B) {
+			benchForEachBackend(b, func(b *testing.B, newCache func(dir string, ttl int) *Cache) {
+				dir := b.TempDir()
+				c := NewWithOptions(dir, 3600, Options{Store: newCache(dir, 3600).store, Compression: algorithm})
+
+				b.SetBytes(int64(len(data)))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					key := fmt.Sprintf("key-%d", i%100)
+					if err := c.Set(key, data); err != nil {
+						b.Fatalf("Set failed: %v", err)
+					}
+					if _, found := c.Get(key); !found {
+						b.Fatal("Get missed")
+					}
+				}
+				b.StopTimer()
+
+				stored, _, _ := c.store.Get("key-0")
+				b.ReportMetric(100*float64(len(stored))/float64(len(data)), "stored-%")
+			})
+		})
 	}
 }
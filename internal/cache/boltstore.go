@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltFile is the name of the BoltStore database in the cache directory
+const boltFile = "cache.db"
+
+// boltLockTimeout bounds how long opening the database waits for another
+// process holding it
+const boltLockTimeout = 10 * time.Second
+
+var (
+	boltDataBucket = []byte("data")
+	boltMetaBucket = []byte("meta")
+	// boltAccessBucket holds the access times recorded by Touch, as unix
+	// seconds, apart from the metadata so that reads rewrite little
+	boltAccessBucket = []byte("access")
+)
+
+// BoltStore keeps every entry in a single bbolt database file. The database
+// is only open for the duration of each transaction: read-only for reads,
+// which other processes can share, and for writing for writes, which lock
+// other processes out until the write is done.
+type BoltStore struct {
+	path string
+
+	// mu lets reads run together and writes alone, as the file lock taken
+	// by each open would otherwise block the process on itself
+	mu sync.RWMutex
+}
+
+// NewBoltStore returns a store in the database file at path. The file and
+// its directory are created on first write.
+func NewBoltStore(path string) *BoltStore {
+	return &BoltStore{path: path}
+}
+
+// withDB opens the database, calls fn with it and closes it again. A missing
+// database is only created for writing: reads of it call fn with nil.
+func (s *BoltStore) withDB(writable bool, fn func(db *bolt.DB) error) (err error) {
+	if writable {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+			return fmt.Errorf("failed to create cache directory: %w", err)
+		}
+	} else {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		if _, err := os.Stat(s.path); os.IsNotExist(err) {
+			return fn(nil)
+		}
+	}
+
+	opts := &bolt.Options{Timeout: boltLockTimeout, ReadOnly: !writable, NoFreelistSync: true}
+	db, err := bolt.Open(s.path, 0600, opts)
+	if err != nil {
+		return fmt.Errorf("failed to open cache database: %w", err)
+	}
+	defer func() {
+		if closeErr := db.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return fn(db)
+}
+
+// view runs fn in a read-only transaction. A missing database or bucket
+// reads as empty: fn receives nil buckets.
+func (s *BoltStore) view(fn func(data, meta, access *bolt.Bucket) error) error {
+	return s.withDB(false, func(db *bolt.DB) error {
+		if db == nil {
+			return fn(nil, nil, nil)
+		}
+		return db.View(func(tx *bolt.Tx) error {
+			return fn(tx.Bucket(boltDataBucket), tx.Bucket(boltMetaBucket), tx.Bucket(boltAccessBucket))
+		})
+	})
+}
+
+// update runs fn in a read-write transaction, creating the database and its
+// buckets as needed
+func (s *BoltStore) update(fn func(data, meta, access *bolt.Bucket) error) error {
+	return s.withDB(true, func(db *bolt.DB) error {
+		return db.Update(func(tx *bolt.Tx) error {
+			data, err := tx.CreateBucketIfNotExists(boltDataBucket)
+			if err != nil {
+				return err
+			}
+			meta, err := tx.CreateBucketIfNotExists(boltMetaBucket)
+			if err != nil {
+				return err
+			}
+			access, err := tx.CreateBucketIfNotExists(boltAccessBucket)
+			if err != nil {
+				return err
+			}
+			return fn(data, meta, access)
+		})
+	})
+}
+
+// Get implements Store
+func (s *BoltStore) Get(key string) ([]byte, CacheMetadata, bool) {
+	var data []byte
+	var meta CacheMetadata
+
+	err := s.view(func(dataBucket, metaBucket, accessBucket *bolt.Bucket) error {
+		if dataBucket == nil || metaBucket == nil {
+			return errNoEntry
+		}
+		d, m := dataBucket.Get([]byte(key)), metaBucket.Get([]byte(key))
+		if d == nil || m == nil {
+			return errNoEntry
+		}
+		if err := json.Unmarshal(m, &meta); err != nil {
+			return err
+		}
+		// Values are only valid during the transaction
+		data = append([]byte(nil), d...)
+		return nil
+	})
+	if err != nil {
+		return nil, CacheMetadata{}, false
+	}
+
+	return data, meta, true
+}
+
+// errNoEntry ends a lookup that found nothing
+var errNoEntry = errors.New("no cache entry")
+
+// Put implements Store
+func (s *BoltStore) Put(key string, data []byte, meta CacheMetadata) error {
+	metaData, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache metadata: %w", err)
+	}
+
+	err = s.update(func(dataBucket, metaBucket, accessBucket *bolt.Bucket) error {
+		if err := dataBucket.Put([]byte(key), data); err != nil {
+			return err
+		}
+		return metaBucket.Put([]byte(key), metaData)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// SetMetadata implements Store
+func (s *BoltStore) SetMetadata(key string, meta CacheMetadata) error {
+	metaData, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache metadata: %w", err)
+	}
+
+	err = s.update(func(dataBucket, metaBucket, accessBucket *bolt.Bucket) error {
+		if dataBucket.Get([]byte(key)) == nil {
+			return errNoEntry
+		}
+		return metaBucket.Put([]byte(key), metaData)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write cache metadata: %w", err)
+	}
+	return nil
+}
+
+// Touch implements Store
+func (s *BoltStore) Touch(key string, at time.Time) error {
+	err := s.update(func(dataBucket, metaBucket, accessBucket *bolt.Bucket) error {
+		if dataBucket.Get([]byte(key)) == nil {
+			return nil
+		}
+		return accessBucket.Put([]byte(key), binary.BigEndian.AppendUint64(nil, uint64(at.Unix())))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record cache access: %w", err)
+	}
+	return nil
+}
+
+// Delete implements Store
+func (s *BoltStore) Delete(key string) error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil
+	}
+
+	return s.update(func(dataBucket, metaBucket, accessBucket *bolt.Bucket) error {
+		for _, b := range []*bolt.Bucket{dataBucket, metaBucket, accessBucket} {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// List implements Store. LastAccess is the time recorded by Touch if that
+// is later.
+func (s *BoltStore) List() ([]Info, error) {
+	var infos []Info
+
+	err := s.view(func(dataBucket, metaBucket, accessBucket *bolt.Bucket) error {
+		if dataBucket == nil || metaBucket == nil {
+			return nil
+		}
+		return metaBucket.ForEach(func(k, v []byte) error {
+			data := dataBucket.Get(k)
+			if data == nil {
+				return nil
+			}
+			var meta CacheMetadata
+			if json.Unmarshal(v, &meta) != nil {
+				return nil
+			}
+			if accessBucket != nil {
+				if at := accessBucket.Get(k); len(at) == 8 {
+					meta.LastAccess = max(meta.LastAccess, int64(binary.BigEndian.Uint64(at)))
+				}
+			}
+			infos = append(infos, Info{Key: string(k), CacheMetadata: meta, Size: int64(len(data))})
+			return nil
+		})
+	})
+
+	return infos, err
+}
+
+// Clear implements Store
+func (s *BoltStore) Clear() error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil
+	}
+
+	return s.update(func(dataBucket, metaBucket, accessBucket *bolt.Bucket) error {
+		for _, b := range []*bolt.Bucket{dataBucket, metaBucket, accessBucket} {
+			var keys [][]byte
+			if err := b.ForEach(func(k, _ []byte) error {
+				keys = append(keys, append([]byte(nil), k...))
+				return nil
+			}); err != nil {
+				return err
+			}
+			for _, k := range keys {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
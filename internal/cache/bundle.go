@@ -0,0 +1,276 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Bundles are gzip-compressed tar archives holding cache entries so that a
+// warmed cache can be shared. The first member is manifest.json, a
+// BundleManifest. Each entry follows as two members, in this order:
+//
+//	entries/<key>.json  the entry's BundleEntry record
+//	entries/<key>.data  the response body, uncompressed
+//
+// Bundles only import into caches of the same SchemaVersion.
+
+// BundleFormat is the version of the bundle layout written by Export
+const BundleFormat = 1
+
+// bundleManifest is the name of the manifest member
+const bundleManifest = "manifest.json"
+
+// validKey matches keys that are safe to use as file names
+var validKey = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// BundleManifest describes a bundle
+type BundleManifest struct {
+	Format        int   `json:"format"`
+	SchemaVersion int   `json:"schema_version"`
+	CreatedAt     int64 `json:"created_at"`
+}
+
+// BundleEntry is the record stored for each entry in a bundle
+type BundleEntry struct {
+	Key string `json:"key"`
+
+	// Request is the canonical request; Endpoint and Params are derived
+	// from it for readers of the bundle and ignored on import
+	Request  string     `json:"request,omitempty"`
+	Endpoint string     `json:"endpoint,omitempty"`
+	Params   url.Values `json:"params,omitempty"`
+	// BaseURL is the namespace the entry was written under. Entries without
+	// one are imported into the namespace of the importing cache.
+	BaseURL string `json:"base_url,omitempty"`
+
+	CreatedAt    int64  `json:"created_at"`
+	TTL          int    `json:"ttl"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// ImportResult summarizes an Import
+type ImportResult struct {
+	// Imported counts entries written from the bundle
+	Imported int `json:"imported"`
+	// Kept counts bundle entries skipped because the cache already held
+	// one for the same key created at the same time or later
+	Kept int `json:"kept"`
+}
+
+// Export writes every entry, expired or not, to w as a bundle and reports
+// how many were written
+func (c *Cache) Export(w io.Writer) (int, error) {
+	infos, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+
+	manifest := BundleManifest{
+		Format:        BundleFormat,
+		SchemaVersion: SchemaVersion,
+		CreatedAt:     now.Unix(),
+	}
+	if err := writeJSONMember(tw, bundleManifest, manifest, now); err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	for _, info := range infos {
+		data, err := c.Data(info.Key)
+		if err != nil {
+			// Deleted or corrupted since it was listed
+			continue
+		}
+
+		record := BundleEntry{
+			Key:          info.Key,
+			Request:      info.Request,
+			BaseURL:      info.Namespace,
+			CreatedAt:    info.CreatedAt,
+			TTL:          info.TTL,
+			ETag:         info.ETag,
+			LastModified: info.LastModified,
+		}
+		if info.Request != "" {
+			record.Endpoint = info.Endpoint()
+			record.Params = info.Params()
+		}
+
+		name := path.Join("entries", info.Key)
+		if err := writeJSONMember(tw, name+".json", record, now); err != nil {
+			return 0, err
+		}
+		if err := writeMember(tw, name+".data", data, now); err != nil {
+			return 0, err
+		}
+		exported++
+	}
+
+	if err := tw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return exported, nil
+}
+
+// writeJSONMember adds v, encoded as JSON, to the archive as name
+func writeJSONMember(tw *tar.Writer, name string, v interface{}, modTime time.Time) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeMember(tw, name, append(data, '\n'), modTime)
+}
+
+// writeMember adds data to the archive as name
+func writeMember(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// Import merges the entries of the bundle read from r into the cache. An
+// entry replaces the cached one for the same key only if it was created
+// later. Entries keep the base URL they were exported under, so they are
+// used when the CLI points at that API.
+func (c *Cache) Import(r io.Reader) (ImportResult, error) {
+	var result ImportResult
+	c.migrate(true)
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return result, fmt.Errorf("not a cache bundle: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+	tr := tar.NewReader(gz)
+
+	var manifest BundleManifest
+	if err := readJSONMember(tr, bundleManifest, &manifest); err != nil {
+		return result, err
+	}
+	if manifest.Format != BundleFormat {
+		return result, fmt.Errorf("unsupported bundle format %d", manifest.Format)
+	}
+	if manifest.SchemaVersion != SchemaVersion {
+		return result, fmt.Errorf("bundle has cache schema version %d, this CLI uses %d", manifest.SchemaVersion, SchemaVersion)
+	}
+
+	for {
+		var record BundleEntry
+		err := readJSONMember(tr, "", &record)
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+
+		hdr, err := tr.Next()
+		if err != nil {
+			return result, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if hdr.Name != path.Join("entries", record.Key)+".data" {
+			return result, fmt.Errorf("invalid bundle: expected data for %s, got %s", record.Key, hdr.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return result, fmt.Errorf("failed to read bundle: %w", err)
+		}
+
+		imported, err := c.importEntry(record, data)
+		if err != nil {
+			return result, err
+		}
+		if imported {
+			result.Imported++
+		} else {
+			result.Kept++
+		}
+	}
+}
+
+// readJSONMember decodes the next archive member into v. When name is empty
+// any entries/*.json member is accepted. It returns io.EOF at the end of
+// the archive.
+func readJSONMember(tr *tar.Reader, name string, v interface{}) error {
+	hdr, err := tr.Next()
+	if errors.Is(err, io.EOF) && name == "" {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	if name != "" && hdr.Name != name {
+		return fmt.Errorf("invalid bundle: expected %s, got %s", name, hdr.Name)
+	}
+	if name == "" && (!strings.HasPrefix(hdr.Name, "entries/") || !strings.HasSuffix(hdr.Name, ".json")) {
+		return fmt.Errorf("invalid bundle: unexpected member %s", hdr.Name)
+	}
+
+	if err := json.NewDecoder(tr).Decode(v); err != nil {
+		return fmt.Errorf("invalid bundle: %s: %w", hdr.Name, err)
+	}
+	return nil
+}
+
+// importEntry stores a bundle entry unless the cache already holds one for
+// its key that is at least as new, and reports whether it was stored
+func (c *Cache) importEntry(record BundleEntry, data []byte) (bool, error) {
+	namespace := strings.TrimSuffix(record.BaseURL, "/")
+	if namespace == "" {
+		namespace = c.opts.Namespace
+	}
+
+	// Entries with a request are keyed the way this CLI keys them
+	key := record.Key
+	if record.Request != "" {
+		key = requestKey(namespace, record.Request)
+	}
+	if !validKey.MatchString(key) {
+		return false, fmt.Errorf("invalid bundle: invalid key %q", key)
+	}
+
+	if _, existing, ok := c.store.Get(key); ok && existing.CreatedAt >= record.CreatedAt {
+		return false, nil
+	}
+
+	err := c.SetEntry(key, data, CacheMetadata{
+		CreatedAt:    record.CreatedAt,
+		TTL:          record.TTL,
+		Request:      record.Request,
+		Namespace:    namespace,
+		ETag:         record.ETag,
+		LastModified: record.LastModified,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to import %s: %w", key, err)
+	}
+	return true, nil
+}
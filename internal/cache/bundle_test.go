@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImport(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		srcDir := t.TempDir()
+		src := NewWithOptions(srcDir, 3600, Options{Store: newCache(srcDir, 3600).store, Namespace: "https://grokipedia.com"})
+
+		page := Request{Endpoint: "/api/page", Params: map[string]interface{}{"slug": "Go"}}
+		if err := src.SetEntry(src.Key(page), []byte(`{"found":true}`), CacheMetadata{Request: page.Canonical(), ETag: `"v1"`}); err != nil {
+			t.Fatal(err)
+		}
+		if err := src.Set("raw-key", []byte(`"raw"`)); err != nil {
+			t.Fatal(err)
+		}
+
+		var bundle bytes.Buffer
+		n, err := src.Export(&bundle)
+		if err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		if n != 2 {
+			t.Errorf("Export() = %d, want 2", n)
+		}
+
+		dstDir := t.TempDir()
+		dst := NewWithOptions(dstDir, 3600, Options{Store: newCache(dstDir, 3600).store, Namespace: "https://grokipedia.com"})
+		result, err := dst.Import(bytes.NewReader(bundle.Bytes()))
+		if err != nil {
+			t.Fatalf("Import() error = %v", err)
+		}
+		if result != (ImportResult{Imported: 2}) {
+			t.Errorf("Import() = %+v, want 2 imported", result)
+		}
+
+		entry, found := dst.Lookup(dst.Key(page))
+		if !found || string(entry.Data) != `{"found":true}` {
+			t.Fatalf("Expected imported page, got %+v", entry)
+		}
+		if entry.ETag != `"v1"` || entry.Namespace != "https://grokipedia.com" || entry.Request != page.Canonical() {
+			t.Errorf("Expected metadata to survive the round trip, got %+v", entry.CacheMetadata)
+		}
+		if data, found := dst.Get("raw-key"); !found || string(data) != `"raw"` {
+			t.Errorf("Expected entry without a request to keep its key, got %q", data)
+		}
+
+		// A second import keeps what is already there
+		result, err = dst.Import(bytes.NewReader(bundle.Bytes()))
+		if err != nil {
+			t.Fatalf("Import() error = %v", err)
+		}
+		if result != (ImportResult{Kept: 2}) {
+			t.Errorf("Import() = %+v, want 2 kept", result)
+		}
+	})
+}
+
+func TestImportNewestWins(t *testing.T) {
+	req := Request{Endpoint: "/api/page", Params: map[string]interface{}{"slug": "Go"}}
+	now := time.Now().Unix()
+
+	bundleWith := func(createdAt int64, data string) []byte {
+		c := New(t.TempDir(), 3600)
+		if err := c.SetEntry(c.Key(req), []byte(data), CacheMetadata{Request: req.Canonical(), CreatedAt: createdAt}); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := c.Export(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	c := New(t.TempDir(), 3600)
+	if err := c.SetEntry(c.Key(req), []byte(`"local"`), CacheMetadata{Request: req.Canonical(), CreatedAt: now - 60}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Import(bytes.NewReader(bundleWith(now-120, `"older"`))); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := c.Get(c.Key(req)); string(data) != `"local"` {
+		t.Errorf("Expected older bundle entry to be ignored, got %s", data)
+	}
+
+	if _, err := c.Import(bytes.NewReader(bundleWith(now, `"newer"`))); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := c.Get(c.Key(req)); string(data) != `"newer"` {
+		t.Errorf("Expected newer bundle entry to replace the local one, got %s", data)
+	}
+}
+
+func TestImportKeepsBaseURL(t *testing.T) {
+	req := Request{Endpoint: "/api/constants"}
+
+	staging := NewWithOptions(t.TempDir(), 3600, Options{Namespace: "https://staging.example.com"})
+	if err := staging.SetEntry(staging.Key(req), []byte(`{}`), CacheMetadata{Request: req.Canonical()}); err != nil {
+		t.Fatal(err)
+	}
+	var bundle bytes.Buffer
+	if _, err := staging.Export(&bundle); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	prod := NewWithOptions(dir, 3600, Options{Namespace: "https://grokipedia.com"})
+	if _, err := prod.Import(&bundle); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := prod.Get(prod.Key(req)); found {
+		t.Error("Expected entry from another API not to answer requests to this one")
+	}
+	if _, found := NewWithOptions(dir, 3600, Options{Namespace: "https://staging.example.com"}).Get(staging.Key(req)); !found {
+		t.Error("Expected entry to be used when pointing at the API it came from")
+	}
+}
+
+func TestImportWithoutBaseURL(t *testing.T) {
+	req := Request{Endpoint: "/api/constants"}
+
+	src := New(t.TempDir(), 3600)
+	if err := src.SetEntry(src.Key(req), []byte(`{}`), CacheMetadata{Request: req.Canonical()}); err != nil {
+		t.Fatal(err)
+	}
+	var bundle bytes.Buffer
+	if _, err := src.Export(&bundle); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewWithOptions(t.TempDir(), 3600, Options{Namespace: "https://grokipedia.com"})
+	if _, err := c.Import(&bundle); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, found := c.Lookup(c.Key(req))
+	if !found {
+		t.Fatal("Expected entry without a base URL to answer requests to the importing cache's API")
+	}
+	if entry.Namespace != "https://grokipedia.com" {
+		t.Errorf("Expected namespace https://grokipedia.com, got %q", entry.Namespace)
+	}
+}
+
+func TestImportInvalidBundle(t *testing.T) {
+	archive := func(members ...string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		for i := 0; i < len(members); i += 2 {
+			_ = tw.WriteHeader(&tar.Header{Name: members[i], Mode: 0600, Size: int64(len(members[i+1]))})
+			_, _ = tw.Write([]byte(members[i+1]))
+		}
+		_ = tw.Close()
+		_ = gz.Close()
+		return buf.Bytes()
+	}
+	manifest := `{"format": 1, "schema_version": 1}`
+
+	tests := []struct {
+		name   string
+		bundle []byte
+		want   string
+	}{
+		{name: "not gzip", bundle: []byte("plain text"), want: "not a cache bundle"},
+		{name: "no manifest", bundle: archive("entries/a.json", `{}`), want: "expected manifest.json"},
+		{name: "other schema", bundle: archive("manifest.json", `{"format": 1, "schema_version": 99}`), want: "schema version 99"},
+		{name: "unsafe key", bundle: archive("manifest.json", manifest,
+			"entries/x.json", `{"key": "../escape"}`, "entries/../escape.data", `{}`), want: "expected data"},
+		{name: "invalid key", bundle: archive("manifest.json", manifest,
+			"entries/x.json", `{"key": "/etc"}`, "entries/etc.data", `{}`), want: "invalid key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(t.TempDir(), 3600).Import(bytes.NewReader(tt.bundle))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Import() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
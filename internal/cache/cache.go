@@ -1,42 +1,214 @@
 package cache
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
-	"os"
-	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/grokipedia/cli/internal/api"
 )
 
-// Cache handles file-based caching with TTL support
+// Cache handles caching with TTL support on top of a Store
 type Cache struct {
-	dir string
-	ttl int // seconds
+	dir   string
+	ttl   int // seconds
+	opts  Options
+	store Store
+
+	// pending tracks background revalidations started by Fetch
+	pending sync.WaitGroup
+	// written is set when SetEntry stores an entry, for Wait to compact
+	written atomic.Bool
+
+	// migrateMu guards migrated, set once the directory is known to hold
+	// entries of the current schema version
+	migrateMu sync.Mutex
+	migrated  bool
+
+	// hits and misses count the lookups made by Fetch since the shared
+	// counters were last updated; see Wait
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// Options configures optional cache behaviour
+type Options struct {
+	// StaleIfError is how long past expiry an entry may still be served
+	// when refreshing it fails because the API is unavailable
+	StaleIfError time.Duration
+	// StaleWhileRevalidate is how long past expiry an entry is served
+	// immediately while it is refreshed in the background
+	StaleWhileRevalidate time.Duration
+	// Warnings, if set, receives a line whenever stale data is served
+	// because of an error
+	Warnings io.Writer
+	// Context bounds background revalidations, which outlive the call
+	// that started them: they are cancelled when it is done. Nil means
+	// context.Background().
+	Context context.Context
+
+	// MaxSize bounds the total size in bytes of cached responses; zero
+	// means unlimited
+	MaxSize int64
+	// MaxEntries bounds the number of cached entries; zero means unlimited
+	MaxEntries int
+
+	// Store holds the entries; nil uses a DirStore in the cache directory
+	Store Store
+
+	// TTLByEndpoint overrides the TTL in seconds for entries whose request
+	// is for the given endpoint path. Non-positive values are ignored.
+	TTLByEndpoint map[string]int
+
+	// Offline makes Fetch answer only from stored entries, expired or not,
+	// and never call the API; a missing entry is an api.NotCachedError.
+	// Expired entries are kept rather than deleted.
+	Offline bool
+
+	// Compression is the algorithm new entries are stored with: one of
+	// Compressions, or empty for none. Entries are read back whatever
+	// they were stored with.
+	Compression string
+
+	// Namespace separates entries written for different API servers,
+	// normally the API base URL. It is part of every key.
+	Namespace string
+}
+
+// EndpointTTLs converts TTLs keyed by endpoint name, as in configuration,
+// into TTLs keyed by endpoint path for Options.TTLByEndpoint
+func EndpointTTLs(byName map[string]int) (map[string]int, error) {
+	if len(byName) == 0 {
+		return nil, nil
+	}
+
+	byPath := make(map[string]int, len(byName))
+	for name, ttl := range byName {
+		path, ok := api.EndpointPath(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown endpoint '%s' in cache TTLs", name)
+		}
+		byPath[path] = ttl
+	}
+	return byPath, nil
 }
 
 // CacheMetadata stores cache entry metadata
 type CacheMetadata struct {
 	CreatedAt int64 `json:"created_at"`
 	TTL       int   `json:"ttl"`
+
+	// LastAccess is when the entry was last written, in unix seconds.
+	// Stores report later reads recorded with Store.Touch in its place.
+	// Compaction evicts the least recently accessed entries first.
+	LastAccess int64 `json:"last_access,omitempty"`
+
+	// Request is the canonical request the entry answers, as produced by
+	// Request.Canonical; empty for entries written without one
+	Request string `json:"request,omitempty"`
+	// Namespace is the Options.Namespace, normally the API base URL, the
+	// entry was written under
+	Namespace string `json:"namespace,omitempty"`
+
+	// Response validators used to revalidate the entry once it expires
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+
+	// Encoding is the compression the stored data is encoded with, or
+	// empty if it is stored as is
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Endpoint returns the API path of the cached request, or "" if the entry
+// was written without its request
+func (m CacheMetadata) Endpoint() string {
+	endpoint, _, _ := strings.Cut(m.Request, "?")
+	return endpoint
+}
+
+// Params returns the query parameters of the cached request
+func (m CacheMetadata) Params() url.Values {
+	_, query, _ := strings.Cut(m.Request, "?")
+	values, _ := url.ParseQuery(query)
+	return values
+}
+
+// Revalidatable reports whether the entry can be refreshed with a
+// conditional request instead of being downloaded again
+func (m CacheMetadata) Revalidatable() bool {
+	return m.ETag != "" || m.LastModified != ""
+}
+
+// Entry is a cached value together with its metadata
+type Entry struct {
+	Data []byte
+	CacheMetadata
+
+	// Expired is true once the entry has outlived its TTL
+	Expired bool
+}
+
+// Staleness returns how long ago the entry expired, or zero if it has not
+func (e *Entry) Staleness(now time.Time) time.Duration {
+	expiry := time.Unix(e.CreatedAt+int64(e.TTL), 0)
+	if !e.Expired || now.Before(expiry) {
+		return 0
+	}
+	return now.Sub(expiry)
 }
 
 // New creates a new Cache instance
 func New(dir string, ttl int) *Cache {
+	return NewWithOptions(dir, ttl, Options{})
+}
+
+// NewWithOptions creates a new Cache instance with optional behaviour
+func NewWithOptions(dir string, ttl int, opts Options) *Cache {
+	store := opts.Store
+	if store == nil {
+		store = NewDirStore(dir)
+	}
+
+	opts.Namespace = strings.TrimSuffix(opts.Namespace, "/")
+
 	return &Cache{
-		dir: dir,
-		ttl: ttl,
+		dir:   dir,
+		ttl:   ttl,
+		opts:  opts,
+		store: store,
 	}
 }
 
-// GenerateKey creates a cache key from endpoint and parameters
-func (c *Cache) GenerateKey(endpoint string, params map[string]interface{}) string {
+// TTLFor returns the TTL in seconds for entries answering requests to
+// endpoint
+func (c *Cache) TTLFor(endpoint string) int {
+	if ttl := c.opts.TTLByEndpoint[endpoint]; ttl > 0 {
+		return ttl
+	}
+	return c.ttl
+}
+
+// Request identifies the API request a cache entry answers
+type Request struct {
+	Endpoint string
+	Params   map[string]interface{}
+}
+
+// Canonical returns the endpoint followed by the non-nil parameters as a
+// query string sorted by name. It is what GenerateKey hashes and what is
+// stored in CacheMetadata.Request.
+func (r Request) Canonical() string {
 	// Sort params by key name for canonicalization
-	keys := make([]string, 0, len(params))
-	for k := range params {
+	keys := make([]string, 0, len(r.Params))
+	for k := range r.Params {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
@@ -44,125 +216,183 @@ func (c *Cache) GenerateKey(endpoint string, params map[string]interface{}) stri
 	// Build query string
 	values := url.Values{}
 	for _, k := range keys {
-		if v, ok := params[k]; ok && v != nil {
+		if v, ok := r.Params[k]; ok && v != nil {
 			values.Set(k, fmt.Sprintf("%v", v))
 		}
 	}
 
-	// Create canonical string
-	canonical := endpoint + "?" + values.Encode()
+	return r.Endpoint + "?" + values.Encode()
+}
+
+// Key returns the cache key for the request. The key covers the schema
+// version and the namespace as well as the request, so entries written for
+// another API server or by a CLI with differently shaped models never match.
+func (c *Cache) Key(req Request) string {
+	return requestKey(c.opts.Namespace, req.Canonical())
+}
 
+// requestKey returns the key for the canonical request in namespace
+func requestKey(namespace, canonical string) string {
 	// Hash with SHA256, take first 12 chars
-	hash := sha256.Sum256([]byte(canonical))
+	hash := sha256.Sum256([]byte(fmt.Sprintf("v%d\n%s\n%s", SchemaVersion, namespace, canonical)))
 	return hex.EncodeToString(hash[:])[:12]
 }
 
+// GenerateKey creates a cache key from endpoint and parameters
+func (c *Cache) GenerateKey(endpoint string, params map[string]interface{}) string {
+	return c.Key(Request{Endpoint: endpoint, Params: params})
+}
+
 // Get retrieves a cached value if it exists and is not expired
 func (c *Cache) Get(key string) ([]byte, bool) {
-	dataPath := filepath.Join(c.dir, key+".json")
-	metaPath := filepath.Join(c.dir, key+".meta")
-
-	// Check if data file exists
-	data, err := os.ReadFile(dataPath)
-	if err != nil {
+	entry, found := c.Lookup(key)
+	if !found || entry.Expired {
 		return nil, false
 	}
+	return entry.Data, true
+}
 
-	// Read and validate metadata
-	metaData, err := os.ReadFile(metaPath)
-	if err != nil {
-		// Metadata missing, treat as expired
-		_ = os.Remove(dataPath)
+// Lookup retrieves a cached entry whether or not it has expired. Expired
+// entries are kept while they can be revalidated with a conditional request
+// or served stale, and deleted once they can never be reused.
+func (c *Cache) Lookup(key string) (*Entry, bool) {
+	c.migrate(false)
+
+	data, meta, ok := c.store.Get(key)
+	if !ok {
 		return nil, false
 	}
 
-	var meta CacheMetadata
-	if err := json.Unmarshal(metaData, &meta); err != nil {
-		// Corrupt metadata, delete files
-		_ = os.Remove(dataPath)
-		_ = os.Remove(metaPath)
+	data, err := decompress(meta.Encoding, data)
+	if err != nil {
+		// Undecodable, delete entry
+		_ = c.store.Delete(key)
 		return nil, false
 	}
 
+	entry := &Entry{Data: data, CacheMetadata: meta}
+
 	// Check if expired
 	if c.ttl > 0 {
 		age := time.Now().Unix() - meta.CreatedAt
 		if age > int64(meta.TTL) {
-			// Expired, delete files
-			_ = os.Remove(dataPath)
-			_ = os.Remove(metaPath)
-			return nil, false
+			staleness := time.Duration(age-int64(meta.TTL)) * time.Second
+			if !c.opts.Offline && !meta.Revalidatable() && staleness > c.maxStaleness() {
+				// Expired, delete entry
+				_ = c.store.Delete(key)
+				return nil, false
+			}
+			entry.Expired = true
 		}
 	}
 
-	return data, true
+	c.touch(key)
+
+	return entry, true
+}
+
+// touch records that the entry for key was accessed now, for compaction to
+// evict the least recently used entries. Unbounded caches never compact, so
+// nothing is recorded for them.
+func (c *Cache) touch(key string) {
+	if !c.bounded() {
+		return
+	}
+	_ = c.store.Touch(key, time.Now())
+}
+
+// maxStaleness returns how long past expiry an entry may still be served
+func (c *Cache) maxStaleness() time.Duration {
+	return max(c.opts.StaleIfError, c.opts.StaleWhileRevalidate)
 }
 
 // Set stores a value in the cache
 func (c *Cache) Set(key string, data []byte) error {
-	// Ensure cache directory exists
-	if err := os.MkdirAll(c.dir, 0700); err != nil {
-		return fmt.Errorf("failed to create cache directory: %w", err)
-	}
+	return c.SetEntry(key, data, CacheMetadata{})
+}
 
-	dataPath := filepath.Join(c.dir, key+".json")
-	metaPath := filepath.Join(c.dir, key+".meta")
+// SetEntry stores a value in the cache with the given metadata. A zero
+// CreatedAt is filled in with the current time, a zero TTL with the TTL for
+// the endpoint of meta.Request, a zero LastAccess with CreatedAt and an
+// empty Namespace with the cache's namespace. The data is compressed as
+// configured by Options.Compression. When MaxSize or MaxEntries is set, the
+// cache is compacted by Wait.
+func (c *Cache) SetEntry(key string, data []byte, meta CacheMetadata) error {
+	c.migrate(true)
 
-	// Write data file
-	if err := os.WriteFile(dataPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write cache data: %w", err)
+	if meta.CreatedAt == 0 {
+		meta.CreatedAt = time.Now().Unix()
 	}
-
-	// Write metadata file
-	meta := CacheMetadata{
-		CreatedAt: time.Now().Unix(),
-		TTL:       c.ttl,
+	if meta.TTL == 0 {
+		meta.TTL = c.TTLFor(meta.Endpoint())
+	}
+	if meta.LastAccess == 0 {
+		meta.LastAccess = meta.CreatedAt
+	}
+	if meta.Namespace == "" {
+		meta.Namespace = c.opts.Namespace
 	}
 
-	metaData, err := json.Marshal(meta)
+	data, encoding, err := compress(c.opts.Compression, data)
 	if err != nil {
-		// Clean up data file on error
-		_ = os.Remove(dataPath)
-		return fmt.Errorf("failed to marshal cache metadata: %w", err)
+		return fmt.Errorf("failed to compress cache entry: %w", err)
 	}
+	meta.Encoding = encoding
 
-	if err := os.WriteFile(metaPath, metaData, 0600); err != nil {
-		// Clean up data file on error
-		_ = os.Remove(dataPath)
-		return fmt.Errorf("failed to write cache metadata: %w", err)
+	if err := c.store.Put(key, data, meta); err != nil {
+		return err
 	}
 
+	c.written.Store(true)
+
 	return nil
 }
 
-// Delete removes a cached entry
-func (c *Cache) Delete(key string) error {
-	dataPath := filepath.Join(c.dir, key+".json")
-	metaPath := filepath.Join(c.dir, key+".meta")
+// Refresh restarts the TTL of an existing entry, keeping its data and
+// validators. It is used when the server confirms the entry is unchanged.
+func (c *Cache) Refresh(key string) error {
+	_, meta, ok := c.store.Get(key)
+	if !ok {
+		return fmt.Errorf("failed to read cache metadata: no entry for %s", key)
+	}
 
-	_ = os.Remove(dataPath)
-	_ = os.Remove(metaPath)
+	meta.CreatedAt = time.Now().Unix()
+	meta.TTL = c.TTLFor(meta.Endpoint())
+	meta.LastAccess = meta.CreatedAt
 
-	return nil
+	return c.store.SetMetadata(key, meta)
+}
+
+// Delete removes a cached entry
+func (c *Cache) Delete(key string) error {
+	return c.store.Delete(key)
 }
 
 // Clear removes all cached entries
 func (c *Cache) Clear() error {
-	entries, err := os.ReadDir(c.dir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
-	}
+	return c.store.Clear()
+}
 
-	for _, entry := range entries {
-		name := entry.Name()
-		if filepath.Ext(name) == ".json" || filepath.Ext(name) == ".meta" {
-			_ = os.Remove(filepath.Join(c.dir, name))
-		}
-	}
+// Wait blocks until background revalidations finish. It then compacts the
+// cache, if it is bounded and entries were written, and adds the hits and
+// misses counted so far to the counters shared through the cache directory.
+// Call it before exiting so that refreshed entries and the counts are
+// written and the cache stays within its limits. Compacting once here
+// rather than after every write keeps long crawls and imports from listing
+// every entry per entry stored.
+func (c *Cache) Wait() {
+	c.pending.Wait()
+	c.compactIfWritten()
+	c.flushCounters()
+}
 
+// Close calls Wait and then closes the store if it holds resources open.
+// Call it instead of Wait before exiting.
+func (c *Cache) Close() error {
+	c.Wait()
+	if closer, ok := c.store.(io.Closer); ok {
+		return closer.Close()
+	}
 	return nil
 }
 
@@ -170,3 +400,8 @@ func (c *Cache) Clear() error {
 func (c *Cache) IsEnabled() bool {
 	return c.ttl > 0
 }
+
+// IsOffline returns true if the cache answers without calling the API
+func (c *Cache) IsOffline() bool {
+	return c.opts.Offline
+}
@@ -1,7 +1,6 @@
 package cache
 
 import (
-	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -83,60 +82,84 @@ func TestGenerateKeyConsistency(t *testing.T) {
 	}
 }
 
-func TestSetAndGet(t *testing.T) {
-	tmpDir := t.TempDir()
-	c := New(tmpDir, 3600)
+func TestKeyNamespace(t *testing.T) {
+	req := Request{Endpoint: "/api/page", Params: map[string]interface{}{"slug": "Go"}}
 
-	key := "test-key"
-	data := []byte(`{"test": "data"}`)
+	prod := NewWithOptions("/tmp/test", 3600, Options{Namespace: "https://grokipedia.com"})
+	staging := NewWithOptions("/tmp/test", 3600, Options{Namespace: "https://staging.grokipedia.com"})
+	slash := NewWithOptions("/tmp/test", 3600, Options{Namespace: "https://grokipedia.com/"})
 
-	// Set data
-	err := c.Set(key, data)
-	if err != nil {
-		t.Fatalf("Set() error = %v", err)
+	if prod.Key(req) == staging.Key(req) {
+		t.Error("Expected different keys for different namespaces")
 	}
-
-	// Get data
-	got, found := c.Get(key)
-	if !found {
-		t.Error("Get() returned found=false, want true")
+	if prod.Key(req) != slash.Key(req) {
+		t.Error("Expected a trailing slash not to change the key")
 	}
-	if string(got) != string(data) {
-		t.Errorf("Get() = %q, want %q", string(got), string(data))
+	if prod.Key(req) != prod.GenerateKey(req.Endpoint, req.Params) {
+		t.Error("Expected GenerateKey to match Key")
 	}
 }
 
+func TestSetAndGet(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		tmpDir := t.TempDir()
+		c := newCache(tmpDir, 3600)
+
+		key := "test-key"
+		data := []byte(`{"test": "data"}`)
+
+		// Set data
+		err := c.Set(key, data)
+		if err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+
+		// Get data
+		got, found := c.Get(key)
+		if !found {
+			t.Error("Get() returned found=false, want true")
+		}
+		if string(got) != string(data) {
+			t.Errorf("Get() = %q, want %q", string(got), string(data))
+		}
+	})
+}
+
 func TestGetNotFound(t *testing.T) {
-	tmpDir := t.TempDir()
-	c := New(tmpDir, 3600)
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		tmpDir := t.TempDir()
+		c := newCache(tmpDir, 3600)
 
-	_, found := c.Get("non-existent-key")
-	if found {
-		t.Error("Get() returned found=true for non-existent key, want false")
-	}
+		_, found := c.Get("non-existent-key")
+		if found {
+			t.Error("Get() returned found=true for non-existent key, want false")
+		}
+	})
 }
 
 func TestGetExpired(t *testing.T) {
-	tmpDir := t.TempDir()
-	c := New(tmpDir, 1) // 1 second TTL
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		tmpDir := t.TempDir()
+		c := newCache(tmpDir, 1) // 1 second TTL
 
-	key := "expired-key"
-	data := []byte(`{"test": "data"}`)
+		key := "expired-key"
+		data := []byte(`{"test": "data"}`)
 
-	// Set data
-	err := c.Set(key, data)
-	if err != nil {
-		t.Fatalf("Set() error = %v", err)
-	}
+		// Set data
+		err := c.Set(key, data)
+		if err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
 
-	// Wait for expiration
-	time.Sleep(2 * time.Second)
+		// Wait for expiration
+		time.Sleep(2 * time.Second)
 
-	// Get should return not found
-	_, found := c.Get(key)
-	if found {
-		t.Error("Get() returned found=true for expired key, want false")
-	}
+		// Get should return not found
+		_, found := c.Get(key)
+		if found {
+			t.Error("Get() returned found=true for expired key, want false")
+		}
+	})
 }
 
 func TestGetCorruptMetadata(t *testing.T) {
@@ -152,9 +175,9 @@ func TestGetCorruptMetadata(t *testing.T) {
 		t.Fatalf("Set() error = %v", err)
 	}
 
-	// Corrupt the metadata file
-	metaPath := filepath.Join(tmpDir, key+".meta")
-	err = os.WriteFile(metaPath, []byte("invalid json"), 0600)
+	// Corrupt the metadata line of the entry file
+	entryPath := filepath.Join(tmpDir, key+entryExt)
+	err = os.WriteFile(entryPath, []byte("invalid json\n"+string(data)), 0600)
 	if err != nil {
 		t.Fatalf("Failed to corrupt metadata: %v", err)
 	}
@@ -164,61 +187,68 @@ func TestGetCorruptMetadata(t *testing.T) {
 	if found {
 		t.Error("Get() returned found=true for corrupt metadata, want false")
 	}
+	if _, err := os.Stat(entryPath); !os.IsNotExist(err) {
+		t.Error("Expected corrupt entry to be deleted")
+	}
 }
 
 func TestDelete(t *testing.T) {
-	tmpDir := t.TempDir()
-	c := New(tmpDir, 3600)
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		tmpDir := t.TempDir()
+		c := newCache(tmpDir, 3600)
 
-	key := "delete-key"
-	data := []byte(`{"test": "data"}`)
+		key := "delete-key"
+		data := []byte(`{"test": "data"}`)
 
-	// Set data
-	err := c.Set(key, data)
-	if err != nil {
-		t.Fatalf("Set() error = %v", err)
-	}
+		// Set data
+		err := c.Set(key, data)
+		if err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
 
-	// Delete
-	err = c.Delete(key)
-	if err != nil {
-		t.Fatalf("Delete() error = %v", err)
-	}
+		// Delete
+		err = c.Delete(key)
+		if err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
 
-	// Get should return not found
-	_, found := c.Get(key)
-	if found {
-		t.Error("Get() returned found=true after delete, want false")
-	}
+		// Get should return not found
+		_, found := c.Get(key)
+		if found {
+			t.Error("Get() returned found=true after delete, want false")
+		}
+	})
 }
 
 func TestClear(t *testing.T) {
-	tmpDir := t.TempDir()
-	c := New(tmpDir, 3600)
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		tmpDir := t.TempDir()
+		c := newCache(tmpDir, 3600)
+
+		// Set multiple entries
+		for i := 0; i < 3; i++ {
+			key := string(rune('a' + i))
+			err := c.Set(key, []byte("data"))
+			if err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+		}
 
-	// Set multiple entries
-	for i := 0; i < 3; i++ {
-		key := string(rune('a' + i))
-		err := c.Set(key, []byte("data"))
+		// Clear
+		err := c.Clear()
 		if err != nil {
-			t.Fatalf("Set() error = %v", err)
+			t.Fatalf("Clear() error = %v", err)
 		}
-	}
 
-	// Clear
-	err := c.Clear()
-	if err != nil {
-		t.Fatalf("Clear() error = %v", err)
-	}
-
-	// All should be gone
-	for i := 0; i < 3; i++ {
-		key := string(rune('a' + i))
-		_, found := c.Get(key)
-		if found {
-			t.Errorf("Get(%q) returned found=true after clear, want false", key)
+		// All should be gone
+		for i := 0; i < 3; i++ {
+			key := string(rune('a' + i))
+			_, found := c.Get(key)
+			if found {
+				t.Errorf("Get(%q) returned found=true after clear, want false", key)
+			}
 		}
-	}
+	})
 }
 
 func TestIsEnabled(t *testing.T) {
@@ -255,66 +285,120 @@ func TestIsEnabled(t *testing.T) {
 }
 
 func TestSetCreatesDirectory(t *testing.T) {
-	tmpDir := t.TempDir()
-	nestedDir := filepath.Join(tmpDir, "nested", "cache", "dir")
-	c := New(nestedDir, 3600)
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		tmpDir := t.TempDir()
+		nestedDir := filepath.Join(tmpDir, "nested", "cache", "dir")
+		c := newCache(nestedDir, 3600)
 
-	key := "test-key"
-	data := []byte(`{"test": "data"}`)
+		key := "test-key"
+		data := []byte(`{"test": "data"}`)
 
-	// Set data - should create nested directories
-	err := c.Set(key, data)
-	if err != nil {
-		t.Fatalf("Set() error = %v", err)
-	}
+		// Set data - should create nested directories
+		err := c.Set(key, data)
+		if err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
 
-	// Verify directory was created
-	if _, err := os.Stat(nestedDir); os.IsNotExist(err) {
-		t.Error("Set() did not create nested directories")
-	}
+		// Verify directory was created
+		if _, err := os.Stat(nestedDir); os.IsNotExist(err) {
+			t.Error("Set() did not create nested directories")
+		}
 
-	// Verify we can read the data
-	got, found := c.Get(key)
-	if !found {
-		t.Error("Get() returned found=false, want true")
-	}
-	if string(got) != string(data) {
-		t.Errorf("Get() = %q, want %q", string(got), string(data))
-	}
+		// Verify we can read the data
+		got, found := c.Get(key)
+		if !found {
+			t.Error("Get() returned found=false, want true")
+		}
+		if string(got) != string(data) {
+			t.Errorf("Get() = %q, want %q", string(got), string(data))
+		}
+	})
 }
 
 func TestCacheMetadata(t *testing.T) {
-	tmpDir := t.TempDir()
-	c := New(tmpDir, 3600)
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		tmpDir := t.TempDir()
+		c := newCache(tmpDir, 3600)
 
-	key := "metadata-test"
-	data := []byte(`{"test": "data"}`)
+		key := "metadata-test"
+		data := []byte(`{"test": "data"}`)
 
-	beforeSet := time.Now().Unix()
-	err := c.Set(key, data)
-	afterSet := time.Now().Unix()
+		beforeSet := time.Now().Unix()
+		err := c.Set(key, data)
+		afterSet := time.Now().Unix()
 
-	if err != nil {
-		t.Fatalf("Set() error = %v", err)
+		if err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+
+		// Read metadata from the store directly
+		_, meta, ok := c.store.Get(key)
+		if !ok {
+			t.Fatal("Failed to read metadata")
+		}
+
+		if meta.TTL != 3600 {
+			t.Errorf("Metadata TTL = %d, want 3600", meta.TTL)
+		}
+
+		if meta.CreatedAt < beforeSet || meta.CreatedAt > afterSet {
+			t.Errorf("Metadata CreatedAt = %d, expected between %d and %d", meta.CreatedAt, beforeSet, afterSet)
+		}
+	})
+}
+
+func TestEndpointTTLs(t *testing.T) {
+	c := NewWithOptions(t.TempDir(), 3600, Options{TTLByEndpoint: map[string]int{
+		"/api/typeahead": 60,
+		"/api/constants": 0, // ignored
+	}})
+
+	typeahead := Request{Endpoint: "/api/typeahead", Params: map[string]interface{}{"q": "py"}}
+	constants := Request{Endpoint: "/api/constants"}
+
+	tests := []struct {
+		name    string
+		key     string
+		meta    CacheMetadata
+		wantTTL int
+	}{
+		{name: "endpoint override", key: c.Key(typeahead), meta: CacheMetadata{Request: typeahead.Canonical()}, wantTTL: 60},
+		{name: "non-positive override ignored", key: c.Key(constants), meta: CacheMetadata{Request: constants.Canonical()}, wantTTL: 3600},
+		{name: "no request", key: "plain", wantTTL: 3600},
+		{name: "explicit TTL kept", key: "explicit", meta: CacheMetadata{Request: typeahead.Canonical(), TTL: 5}, wantTTL: 5},
 	}
 
-	// Read metadata file directly
-	metaPath := filepath.Join(tmpDir, key+".meta")
-	metaData, err := os.ReadFile(metaPath)
-	if err != nil {
-		t.Fatalf("Failed to read metadata: %v", err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.SetEntry(tt.key, []byte(`{}`), tt.meta); err != nil {
+				t.Fatalf("SetEntry() error = %v", err)
+			}
+			entry, found := c.Lookup(tt.key)
+			if !found || entry.TTL != tt.wantTTL {
+				t.Errorf("Expected TTL %d, got %+v", tt.wantTTL, entry)
+			}
+		})
 	}
 
-	var meta CacheMetadata
-	if err := json.Unmarshal(metaData, &meta); err != nil {
-		t.Fatalf("Failed to unmarshal metadata: %v", err)
+	// Refreshing restarts the endpoint TTL rather than the default
+	if err := c.Refresh(c.Key(typeahead)); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if entry, _ := c.Lookup(c.Key(typeahead)); entry.TTL != 60 {
+		t.Errorf("Expected refreshed TTL 60, got %d", entry.TTL)
 	}
+}
 
-	if meta.TTL != 3600 {
-		t.Errorf("Metadata TTL = %d, want 3600", meta.TTL)
+func TestEndpointTTLsByName(t *testing.T) {
+	got, err := EndpointTTLs(map[string]int{"typeahead": 60, "edits-by-slug": 300})
+	if err != nil {
+		t.Fatalf("EndpointTTLs() error = %v", err)
+	}
+	if got["/api/typeahead"] != 60 || got["/api/list-edit-requests-by-slug"] != 300 || len(got) != 2 {
+		t.Errorf("EndpointTTLs() = %v", got)
 	}
 
-	if meta.CreatedAt < beforeSet || meta.CreatedAt > afterSet {
-		t.Errorf("Metadata CreatedAt = %d, expected between %d and %d", meta.CreatedAt, beforeSet, afterSet)
+	if _, err := EndpointTTLs(map[string]int{"bogus": 1}); err == nil {
+		t.Error("Expected error for unknown endpoint name")
 	}
 }
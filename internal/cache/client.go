@@ -0,0 +1,216 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/grokipedia/cli/internal/api"
+	"golang.org/x/sync/singleflight"
+)
+
+// CachedClient implements api.GrokipediaAPI by answering each call through
+// the cache, fetching from the wrapped client only when Fetch has to.
+// Concurrent identical calls share one fetch.
+type CachedClient struct {
+	client api.GrokipediaAPI
+	cache  *Cache
+	group  singleflight.Group
+
+	// mu guards flights, the contexts of the shared fetches in progress by
+	// request
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is the context of a shared fetch and the number of calls waiting
+// for it
+type flight struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+}
+
+var _ api.GrokipediaAPI = (*CachedClient)(nil)
+
+// NewCachedClient returns a client that caches the responses of client in
+// c. A nil cache only de-duplicates concurrent calls.
+func NewCachedClient(client api.GrokipediaAPI, c *Cache) *CachedClient {
+	return &CachedClient{client: client, cache: c}
+}
+
+// Do returns the value cached for req, calling fetch with the uncached
+// client on a miss; see Fetch. Clients implementing api.Conditional are
+// made conditional on the validators of the entry being revalidated.
+// Concurrent calls for the same request wait for the first one and share its
+// result and error, so the result must be treated as read-only. A call
+// whose context is done stops waiting and returns the context's error while
+// the others still get the result; the shared fetch is cancelled once every
+// call waiting for it has stopped. Use it for responses built from several
+// API calls, such as every page of a listing.
+func Do[T any](ctx context.Context, cc *CachedClient, req Request, fetch func(ctx context.Context, client api.GrokipediaAPI) (T, error)) (T, error) {
+	key := req.Canonical()
+	shared, leave := cc.join(ctx, key)
+	defer leave()
+
+	ch := cc.group.DoChan(key, func() (interface{}, error) {
+		return Fetch(shared, cc.cache, req, func(ctx context.Context, v api.Validators) (T, api.Validators, error) {
+			conditional, ok := cc.client.(api.Conditional)
+			if !ok {
+				result, err := fetch(ctx, cc.client)
+				return result, api.Validators{}, err
+			}
+
+			client := conditional.Conditional(v)
+			result, err := fetch(ctx, client)
+			return result, client.Validators(), err
+		})
+	})
+
+	select {
+	case r := <-ch:
+		result, _ := r.Val.(T)
+		return result, r.Err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// join registers a call waiting for the shared fetch of key and returns
+// the fetch's context, which has the values of the context of the call that
+// started it. leave unregisters the call, cancelling the context when no
+// other call is waiting; later calls then start a fetch of their own rather
+// than join the cancelled one.
+func (cc *CachedClient) join(ctx context.Context, key string) (shared context.Context, leave func()) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	f := cc.flights[key]
+	if f == nil {
+		f = &flight{}
+		f.ctx, f.cancel = context.WithCancel(context.WithoutCancel(ctx))
+		if cc.flights == nil {
+			cc.flights = make(map[string]*flight)
+		}
+		cc.flights[key] = f
+	}
+	f.waiters++
+
+	return f.ctx, func() {
+		cc.mu.Lock()
+		defer cc.mu.Unlock()
+
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			delete(cc.flights, key)
+			cc.group.Forget(key)
+		}
+	}
+}
+
+// SearchContext implements api.GrokipediaAPI
+func (cc *CachedClient) SearchContext(ctx context.Context, query string, limit, offset int) (*api.SearchResponse, error) {
+	req := Request{Endpoint: api.EndpointSearch, Params: map[string]interface{}{
+		"q":      query,
+		"limit":  limit,
+		"offset": offset,
+	}}
+	return Do(ctx, cc, req, func(ctx context.Context, client api.GrokipediaAPI) (*api.SearchResponse, error) {
+		return client.SearchContext(ctx, query, limit, offset)
+	})
+}
+
+// PageContext implements api.GrokipediaAPI. Unlike the API, which reports a
+// missing page with Found unset, it returns an api.NotFoundError, whether
+// the answer came from the API or the cache. Missing pages are not cached.
+func (cc *CachedClient) PageContext(ctx context.Context, slug string, includeContent, validateLinks bool) (*api.PageResponse, error) {
+	req := PageRequest(slug, includeContent, validateLinks)
+	result, err := Do(ctx, cc, req, func(ctx context.Context, client api.GrokipediaAPI) (*api.PageResponse, error) {
+		result, err := client.PageContext(ctx, slug, includeContent, validateLinks)
+		if err != nil {
+			return nil, err
+		}
+		if !result.Found {
+			return nil, &api.NotFoundError{Resource: slug}
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries imported from elsewhere may hold a missing page
+	if !result.Found {
+		return nil, &api.NotFoundError{Resource: slug}
+	}
+	return result, nil
+}
+
+// TypeaheadContext implements api.GrokipediaAPI
+func (cc *CachedClient) TypeaheadContext(ctx context.Context, query string, limit int) (*api.TypeaheadResponse, error) {
+	req := Request{Endpoint: api.EndpointTypeahead, Params: map[string]interface{}{
+		"q":     query,
+		"limit": limit,
+	}}
+	return Do(ctx, cc, req, func(ctx context.Context, client api.GrokipediaAPI) (*api.TypeaheadResponse, error) {
+		return client.TypeaheadContext(ctx, query, limit)
+	})
+}
+
+// ConstantsContext implements api.GrokipediaAPI
+func (cc *CachedClient) ConstantsContext(ctx context.Context) (api.ConstantsResponse, error) {
+	req := Request{Endpoint: api.EndpointConstants, Params: map[string]interface{}{}}
+	return Do(ctx, cc, req, func(ctx context.Context, client api.GrokipediaAPI) (api.ConstantsResponse, error) {
+		return client.ConstantsContext(ctx)
+	})
+}
+
+// EditsContext implements api.GrokipediaAPI
+func (cc *CachedClient) EditsContext(ctx context.Context, limit int, status []string, excludeUsers []string, includeCounts bool) (*api.EditsResponse, error) {
+	return cc.EditsPageContext(ctx, limit, 0, status, excludeUsers, includeCounts)
+}
+
+// EditsPageContext implements api.GrokipediaAPI. The first page shares its
+// cache entry with EditsContext.
+func (cc *CachedClient) EditsPageContext(ctx context.Context, limit, offset int, status []string, excludeUsers []string, includeCounts bool) (*api.EditsResponse, error) {
+	req := Request{Endpoint: api.EndpointEdits, Params: EditsParams(limit, offset, status, excludeUsers, includeCounts)}
+	return Do(ctx, cc, req, func(ctx context.Context, client api.GrokipediaAPI) (*api.EditsResponse, error) {
+		if offset == 0 {
+			return client.EditsContext(ctx, limit, status, excludeUsers, includeCounts)
+		}
+		return client.EditsPageContext(ctx, limit, offset, status, excludeUsers, includeCounts)
+	})
+}
+
+// EditsBySlugContext implements api.GrokipediaAPI
+func (cc *CachedClient) EditsBySlugContext(ctx context.Context, slug string, limit, offset int) (*api.EditsBySlugResponse, error) {
+	req := Request{Endpoint: api.EndpointEditsBySlug, Params: map[string]interface{}{
+		"slug":   slug,
+		"limit":  limit,
+		"offset": offset,
+	}}
+	return Do(ctx, cc, req, func(ctx context.Context, client api.GrokipediaAPI) (*api.EditsBySlugResponse, error) {
+		return client.EditsBySlugContext(ctx, slug, limit, offset)
+	})
+}
+
+// EditsParams returns the cache request parameters of an edits listing.
+// Empty filters and a zero offset are left out.
+func EditsParams(limit, offset int, status []string, excludeUsers []string, includeCounts bool) map[string]interface{} {
+	params := map[string]interface{}{
+		"limit":         limit,
+		"includeCounts": includeCounts,
+	}
+	if offset > 0 {
+		params["offset"] = offset
+	}
+	if len(status) > 0 {
+		params["status"] = strings.Join(status, ",")
+	}
+	if len(excludeUsers) > 0 {
+		params["excludeUsers"] = strings.Join(excludeUsers, ",")
+	}
+	return params
+}
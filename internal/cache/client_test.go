@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/apitest"
+)
+
+// newFixtureFake creates a fake API client seeded from the repository
+// fixtures
+func newFixtureFake(t *testing.T) *apitest.Fake {
+	t.Helper()
+
+	f, err := apitest.NewFromFixtures("../../testdata/fixtures")
+	if err != nil {
+		t.Fatalf("failed to load API fixtures: %v", err)
+	}
+	return f
+}
+
+func TestCachedClientCaches(t *testing.T) {
+	fake := newFixtureFake(t)
+	cc := NewCachedClient(fake, New(t.TempDir(), 3600))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := cc.SearchContext(ctx, "python", 12, 0); err != nil {
+			t.Fatalf("SearchContext() error = %v", err)
+		}
+		if _, err := cc.TypeaheadContext(ctx, "python", 5); err != nil {
+			t.Fatalf("TypeaheadContext() error = %v", err)
+		}
+		if _, err := cc.ConstantsContext(ctx); err != nil {
+			t.Fatalf("ConstantsContext() error = %v", err)
+		}
+		if _, err := cc.EditsContext(ctx, 20, []string{"approved"}, nil, true); err != nil {
+			t.Fatalf("EditsContext() error = %v", err)
+		}
+		if _, err := cc.EditsBySlugContext(ctx, "Python_programming_language", 10, 0); err != nil {
+			t.Fatalf("EditsBySlugContext() error = %v", err)
+		}
+		if _, err := cc.PageContext(ctx, "Python_programming_language", true, true); err != nil {
+			t.Fatalf("PageContext() error = %v", err)
+		}
+	}
+
+	for _, method := range []string{
+		apitest.MethodSearch, apitest.MethodTypeahead, apitest.MethodConstants,
+		apitest.MethodEdits, apitest.MethodEditsBySlug, apitest.MethodPage,
+	} {
+		if got := fake.Calls(method); got != 1 {
+			t.Errorf("%s: expected 1 call, got %d", method, got)
+		}
+	}
+}
+
+func TestCachedClientSingleflight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	fake := apitest.New()
+	fake.ConstantsFunc = func(ctx context.Context) (api.ConstantsResponse, error) {
+		started <- struct{}{}
+		<-release
+		return api.ConstantsResponse{"answer": 42}, nil
+	}
+	cc := NewCachedClient(fake, nil)
+
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make([]api.ConstantsResponse, callers)
+	call := func(i int) {
+		defer wg.Done()
+		results[i], _ = cc.ConstantsContext(context.Background())
+	}
+
+	wg.Add(1)
+	go call(0)
+	<-started
+
+	// The other callers join the fetch already in flight
+	for i := 1; i < callers; i++ {
+		wg.Add(1)
+		go call(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := fake.Calls(apitest.MethodConstants); got != 1 {
+		t.Errorf("Expected 1 call shared by %d callers, got %d", callers, got)
+	}
+	for i, r := range results {
+		if r["answer"] != 42 {
+			t.Errorf("Caller %d got %v, want the shared result", i, r)
+		}
+	}
+}
+
+func TestCachedClientSingleflightCancel(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	fake := apitest.New()
+	fake.ConstantsFunc = func(ctx context.Context) (api.ConstantsResponse, error) {
+		started <- struct{}{}
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return api.ConstantsResponse{"answer": 42}, nil
+	}
+	cc := NewCachedClient(fake, nil)
+
+	// The first caller starts the fetch and gives up on it
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := cc.ConstantsContext(ctx)
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan api.ConstantsResponse, 1)
+	go func() {
+		result, _ := cc.ConstantsContext(context.Background())
+		second <- result
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-firstErr; err != context.Canceled {
+		t.Errorf("Expected the cancelled caller to get %v, got %v", context.Canceled, err)
+	}
+
+	close(release)
+	if r := <-second; r["answer"] != 42 {
+		t.Errorf("Expected the remaining caller to get the shared result, got %v", r)
+	}
+}
+
+func TestCachedClientCancelStopsFetch(t *testing.T) {
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	fake := apitest.New()
+	fake.ConstantsFunc = func(ctx context.Context) (api.ConstantsResponse, error) {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return nil, ctx.Err()
+	}
+	cc := NewCachedClient(fake, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _, _ = cc.ConstantsContext(ctx) }()
+	<-started
+	cancel()
+
+	select {
+	case err := <-stopped:
+		if err != context.Canceled {
+			t.Errorf("Expected the fetch to be cancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the fetch to be cancelled once its only caller gave up")
+	}
+}
+
+func TestCachedClientPageNotFound(t *testing.T) {
+	c := New(t.TempDir(), 3600)
+	cc := NewCachedClient(apitest.New(), c)
+	ctx := context.Background()
+
+	// From the API
+	_, err := cc.PageContext(ctx, "Missing", false, true)
+	if api.GetExitCode(err) != api.ExitNotFound {
+		t.Errorf("Expected not found error from the API, got %v", err)
+	}
+	if infos, _ := c.Entries(); len(infos) != 0 {
+		t.Errorf("Expected missing page not to be cached, got %d entries", len(infos))
+	}
+
+	// From a cached entry recording a missing page
+	req := PageRequest("Imported", false, true)
+	if err := c.SetEntry(c.Key(req), []byte(`{"found":false}`), CacheMetadata{Request: req.Canonical()}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = cc.PageContext(ctx, "Imported", false, true)
+	if api.GetExitCode(err) != api.ExitNotFound {
+		t.Errorf("Expected not found error from the cache, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/grokipedia/cli/internal/filelock"
+)
+
+// compactFile serializes compactions between processes sharing the cache
+// directory
+const compactFile = "compact.lock"
+
+// bounded reports whether MaxSize or MaxEntries is set
+func (c *Cache) bounded() bool {
+	return c.opts.MaxSize > 0 || c.opts.MaxEntries > 0
+}
+
+// Compact evicts the least recently accessed entries until the cache is
+// within MaxSize and MaxEntries, and reports how many were evicted. It holds
+// an advisory lock so that concurrent processes do not compact at once.
+func (c *Cache) Compact() (int, error) {
+	if !c.bounded() {
+		return 0, nil
+	}
+
+	lock, err := filelock.Lock(filepath.Join(c.dir, compactFile))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	infos, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, info := range infos {
+		size += info.Size
+	}
+	count := len(infos)
+
+	// Least recently accessed first
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].LastAccessTime().Before(infos[j].LastAccessTime())
+	})
+
+	evicted := 0
+	for _, info := range infos {
+		overSize := c.opts.MaxSize > 0 && size > c.opts.MaxSize
+		overCount := c.opts.MaxEntries > 0 && count > c.opts.MaxEntries
+		if !overSize && !overCount {
+			break
+		}
+
+		_ = c.Delete(info.Key)
+		size -= info.Size
+		count--
+		evicted++
+	}
+
+	return evicted, nil
+}
+
+// compactIfWritten runs a compaction if the cache is bounded and an entry
+// has been written since the last one. Failures are ignored: the next
+// invocation that writes tries again.
+func (c *Cache) compactIfWritten() {
+	if c.bounded() && c.written.Swap(false) {
+		_, _ = c.Compact()
+	}
+}
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// setAccessed stores n one-byte entries named key0..key<n-1>, each accessed
+// one minute after the previous
+func setAccessed(t *testing.T, c *Cache, n int) {
+	t.Helper()
+
+	base := time.Now().Add(-time.Hour).Unix()
+	for i := 0; i < n; i++ {
+		meta := CacheMetadata{CreatedAt: base, LastAccess: base + int64(i*60)}
+		if err := c.SetEntry(fmt.Sprintf("key%d", i), []byte("x"), meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Wait()
+}
+
+// keys returns the keys of the entries in c
+func keys(t *testing.T, c *Cache) map[string]bool {
+	t.Helper()
+
+	infos, err := c.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := make(map[string]bool, len(infos))
+	for _, info := range infos {
+		set[info.Key] = true
+	}
+	return set
+}
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		wantEvicted int
+		wantKeys    []string
+	}{
+		{
+			name:        "unbounded",
+			opts:        Options{},
+			wantEvicted: 0,
+			wantKeys:    []string{"key0", "key1", "key2", "key3", "key4"},
+		},
+		{
+			name:        "max entries",
+			opts:        Options{MaxEntries: 3},
+			wantEvicted: 2,
+			wantKeys:    []string{"key2", "key3", "key4"},
+		},
+		{
+			name:        "max size",
+			opts:        Options{MaxSize: 2},
+			wantEvicted: 3,
+			wantKeys:    []string{"key3", "key4"},
+		},
+		{
+			name:        "tighter limit wins",
+			opts:        Options{MaxSize: 4, MaxEntries: 1},
+			wantEvicted: 4,
+			wantKeys:    []string{"key4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			setAccessed(t, New(dir, 3600), 5)
+
+			c := NewWithOptions(dir, 3600, tt.opts)
+			evicted, err := c.Compact()
+			if err != nil {
+				t.Fatalf("Compact() error = %v", err)
+			}
+			if evicted != tt.wantEvicted {
+				t.Errorf("Compact() evicted %d, want %d", evicted, tt.wantEvicted)
+			}
+
+			got := keys(t, c)
+			if len(got) != len(tt.wantKeys) {
+				t.Errorf("Expected keys %v, got %v", tt.wantKeys, got)
+			}
+			for _, k := range tt.wantKeys {
+				if !got[k] {
+					t.Errorf("Expected %s to survive compaction, got %v", k, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLookupRecordsLastAccess(t *testing.T) {
+	dir := t.TempDir()
+	setAccessed(t, New(dir, 3600), 3)
+
+	c := NewWithOptions(dir, 3600, Options{MaxEntries: 2})
+
+	// Reading the least recently used entry makes it the most recent
+	if _, found := c.Get("key0"); !found {
+		t.Fatal("Expected key0 to be cached")
+	}
+
+	if got := lastAccess(t, c, "key0"); time.Since(got) > time.Minute {
+		t.Errorf("Expected LastAccess to be updated, got %v", got)
+	}
+
+	if _, err := c.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	got := keys(t, c)
+	if !got["key0"] || !got["key2"] || got["key1"] {
+		t.Errorf("Expected key1 to be evicted, got %v", got)
+	}
+}
+
+func TestLookupUnboundedRecordsNothing(t *testing.T) {
+	dir := t.TempDir()
+	setAccessed(t, New(dir, 3600), 1)
+	before := lastAccess(t, New(dir, 3600), "key0")
+
+	if _, found := New(dir, 3600).Get("key0"); !found {
+		t.Fatal("Expected key0 to be cached")
+	}
+	if got := lastAccess(t, New(dir, 3600), "key0"); !got.Equal(before) {
+		t.Errorf("Expected no access to be recorded without limits, got %v, was %v", got, before)
+	}
+}
+
+// lastAccess returns the last access time listed for key
+func lastAccess(t *testing.T, c *Cache, key string) time.Time {
+	t.Helper()
+
+	infos, err := c.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range infos {
+		if info.Key == key {
+			return info.LastAccessTime()
+		}
+	}
+	t.Fatalf("Expected %s to be cached", key)
+	return time.Time{}
+}
+
+func TestWaitCompacts(t *testing.T) {
+	c := NewWithOptions(t.TempDir(), 3600, Options{MaxEntries: 2})
+
+	base := time.Now().Add(-time.Hour).Unix()
+	for i := 0; i < 10; i++ {
+		meta := CacheMetadata{CreatedAt: base, LastAccess: base + int64(i*60)}
+		if err := c.SetEntry(fmt.Sprintf("key%d", i), []byte("x"), meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := keys(t, c); len(got) != 10 {
+		t.Errorf("Expected writes not to compact before Wait, got %d entries", len(got))
+	}
+
+	c.Wait()
+
+	got := keys(t, c)
+	if len(got) != 2 || !got["key8"] || !got["key9"] {
+		t.Errorf("Expected the 2 most recently used entries to remain, got %v", got)
+	}
+}
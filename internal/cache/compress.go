@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression algorithms for stored responses
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// Compressions lists the supported compression algorithms
+var Compressions = []string{CompressionNone, CompressionGzip, CompressionZstd}
+
+// ValidateCompression returns an error unless name is a supported
+// compression algorithm or empty, which means none
+func ValidateCompression(name string) error {
+	switch name {
+	case "", CompressionNone, CompressionGzip, CompressionZstd:
+		return nil
+	}
+	return fmt.Errorf("unknown cache compression '%s' (want none, gzip or zstd)", name)
+}
+
+// zstd encoders and decoders are expensive to create and safe for
+// concurrent use, so one of each is shared
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+		return zstd.NewWriter(nil)
+	})
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil)
+	})
+)
+
+// compress encodes data with algorithm. It returns the data unchanged with
+// an empty encoding when compression is off or would not make it smaller.
+func compress(algorithm string, data []byte) ([]byte, string, error) {
+	var compressed []byte
+	switch algorithm {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, "", err
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		compressed = buf.Bytes()
+
+	case CompressionZstd:
+		enc, err := zstdEncoder()
+		if err != nil {
+			return nil, "", err
+		}
+		compressed = enc.EncodeAll(data, nil)
+
+	default:
+		return data, "", nil
+	}
+
+	if len(compressed) >= len(data) {
+		return data, "", nil
+	}
+	return compressed, algorithm, nil
+}
+
+// decompress decodes data stored with encoding, as recorded in
+// CacheMetadata.Encoding
+func decompress(encoding string, data []byte) ([]byte, error) {
+	switch encoding {
+	case "":
+		return data, nil
+
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = r.Close() }()
+		return io.ReadAll(r)
+
+	case CompressionZstd:
+		dec, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeAll(data, nil)
+
+	default:
+		return nil, fmt.Errorf("unknown cache entry encoding '%s'", encoding)
+	}
+}
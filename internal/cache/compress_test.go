@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat(`{"title":"Go","content":"Go is a programming language."}`, 50))
+
+	for _, algorithm := range Compressions {
+		t.Run(algorithm, func(t *testing.T) {
+			stored, encoding, err := compress(algorithm, data)
+			if err != nil {
+				t.Fatalf("compress() error = %v", err)
+			}
+
+			wantEncoding := algorithm
+			if algorithm == CompressionNone {
+				wantEncoding = ""
+			}
+			if encoding != wantEncoding {
+				t.Errorf("compress() encoding = %q, want %q", encoding, wantEncoding)
+			}
+			if encoding != "" && len(stored) >= len(data) {
+				t.Errorf("Expected compressed data to be smaller, got %d >= %d bytes", len(stored), len(data))
+			}
+
+			got, err := decompress(encoding, stored)
+			if err != nil {
+				t.Fatalf("decompress() error = %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Error("decompress() did not return the original data")
+			}
+		})
+	}
+}
+
+func TestCompressIncompressible(t *testing.T) {
+	data := []byte(`{}`)
+
+	stored, encoding, err := compress(CompressionZstd, data)
+	if err != nil {
+		t.Fatalf("compress() error = %v", err)
+	}
+	if encoding != "" || !bytes.Equal(stored, data) {
+		t.Errorf("compress() = %q, %q; want data stored as is", stored, encoding)
+	}
+}
+
+func TestValidateCompression(t *testing.T) {
+	for _, name := range append([]string{""}, Compressions...) {
+		if err := ValidateCompression(name); err != nil {
+			t.Errorf("ValidateCompression(%q) error = %v", name, err)
+		}
+	}
+	if err := ValidateCompression("lz4"); err == nil {
+		t.Error("Expected an error for an unknown compression")
+	}
+}
+
+func TestCompressedEntries(t *testing.T) {
+	data := []byte(strings.Repeat(`{"title":"Go","content":"Go is a programming language."}`, 50))
+
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		dir := t.TempDir()
+		base := newCache(dir, 3600)
+		c := NewWithOptions(dir, 3600, Options{Store: base.store, Compression: CompressionZstd})
+
+		if err := c.Set("page", data); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+
+		stored, meta, ok := c.store.Get("page")
+		if !ok {
+			t.Fatal("Expected entry to be stored")
+		}
+		if meta.Encoding != CompressionZstd || len(stored) >= len(data) {
+			t.Errorf("Expected zstd-compressed data, got encoding %q and %d bytes", meta.Encoding, len(stored))
+		}
+
+		// A cache configured without compression still reads the entry
+		got, found := base.Get("page")
+		if !found || !bytes.Equal(got, data) {
+			t.Error("Get() did not return the original data")
+		}
+		if got, err := base.Data("page"); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("Data() = %d bytes, %v; want the original data", len(got), err)
+		}
+	})
+}
+
+func TestCorruptCompressedEntry(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		c := newCache(t.TempDir(), 3600)
+
+		if err := c.store.Put("bad", []byte("not gzip"), CacheMetadata{CreatedAt: time.Now().Unix(), TTL: 3600, Encoding: CompressionGzip}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, found := c.Get("bad"); found {
+			t.Error("Expected undecodable entry to be a miss")
+		}
+		if _, _, ok := c.store.Get("bad"); ok {
+			t.Error("Expected undecodable entry to be deleted")
+		}
+	})
+}
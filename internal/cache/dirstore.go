@@ -0,0 +1,306 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grokipedia/cli/internal/filelock"
+)
+
+const (
+	// entryExt is the extension of entry files
+	entryExt = ".entry"
+	// tempExt marks entry files that are still being written
+	tempExt = ".tmp"
+	// dirLockFile serializes writers sharing the directory
+	dirLockFile = "entries.lock"
+)
+
+// errCorruptEntry is returned for entry files that cannot be decoded
+var errCorruptEntry = errors.New("corrupt cache entry")
+
+// DirStore keeps each entry as a <key>.entry file in a directory. An entry
+// file holds the metadata as a single line of JSON followed by the data:
+//
+//	{"created_at":1700000000,"ttl":604800,...}
+//	<data>
+//
+// Files are written to a temporary name and renamed into place, so readers
+// never see a partial entry and need no lock. Writers hold an advisory lock
+// on the directory so that concurrent processes can share it safely. The
+// modification time of an entry file is its last access time.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore returns a store in dir. The directory is created on first
+// write.
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{dir: dir}
+}
+
+func (s *DirStore) entryPath(key string) string {
+	return filepath.Join(s.dir, key+entryExt)
+}
+
+// lock takes the directory's writer lock, creating the directory if needed
+func (s *DirStore) lock() (*filelock.File, error) {
+	lock, err := filelock.Lock(filepath.Join(s.dir, dirLockFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock cache directory: %w", err)
+	}
+	return lock, nil
+}
+
+// encodeEntry returns the entry file contents for data and meta
+func encodeEntry(data []byte, meta CacheMetadata) ([]byte, error) {
+	header, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cache metadata: %w", err)
+	}
+
+	buf := make([]byte, 0, len(header)+1+len(data))
+	buf = append(buf, header...)
+	buf = append(buf, '\n')
+	return append(buf, data...), nil
+}
+
+// decodeEntry splits entry file contents into data and metadata
+func decodeEntry(raw []byte) ([]byte, CacheMetadata, error) {
+	var meta CacheMetadata
+
+	header, data, ok := bytes.Cut(raw, []byte{'\n'})
+	if !ok {
+		return nil, meta, errCorruptEntry
+	}
+	if err := json.Unmarshal(header, &meta); err != nil {
+		return nil, meta, errCorruptEntry
+	}
+	return data, meta, nil
+}
+
+// readEntry reads and decodes the entry for key
+func (s *DirStore) readEntry(key string) ([]byte, CacheMetadata, error) {
+	raw, err := os.ReadFile(s.entryPath(key))
+	if err != nil {
+		return nil, CacheMetadata{}, err
+	}
+	return decodeEntry(raw)
+}
+
+// writeEntry atomically replaces the entry for key. The caller must hold
+// the directory lock.
+func (s *DirStore) writeEntry(key string, data []byte, meta CacheMetadata) error {
+	content, err := encodeEntry(data, meta)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, key+".*"+tempExt)
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && meta.LastAccess > 0 {
+		access := time.Unix(meta.LastAccess, 0)
+		err = os.Chtimes(tmp.Name(), access, access)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.entryPath(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// Get implements Store. Entries that cannot be decoded are deleted.
+func (s *DirStore) Get(key string) ([]byte, CacheMetadata, bool) {
+	data, meta, err := s.readEntry(key)
+	if errors.Is(err, errCorruptEntry) {
+		s.deleteIfCorrupt(key)
+	}
+	if err != nil {
+		return nil, CacheMetadata{}, false
+	}
+	return data, meta, true
+}
+
+// deleteIfCorrupt deletes the entry for key if it still cannot be decoded
+// once the directory lock is held, so that an entry replaced in the
+// meantime by another process is kept
+func (s *DirStore) deleteIfCorrupt(key string) {
+	lock, err := s.lock()
+	if err != nil {
+		return
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if _, _, err := s.readEntry(key); errors.Is(err, errCorruptEntry) {
+		_ = os.Remove(s.entryPath(key))
+	}
+}
+
+// Put implements Store
+func (s *DirStore) Put(key string, data []byte, meta CacheMetadata) error {
+	lock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if err := s.writeEntry(key, data, meta); err != nil {
+		return err
+	}
+
+	// Drop the key's files from the former .json/.meta layout, if any
+	_ = os.Remove(filepath.Join(s.dir, key+".json"))
+	_ = os.Remove(filepath.Join(s.dir, key+".meta"))
+
+	return nil
+}
+
+// SetMetadata implements Store
+func (s *DirStore) SetMetadata(key string, meta CacheMetadata) error {
+	lock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	data, _, err := s.readEntry(key)
+	if err != nil {
+		return fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	return s.writeEntry(key, data, meta)
+}
+
+// Touch implements Store by setting the modification time of the entry
+// file, which needs no lock
+func (s *DirStore) Touch(key string, at time.Time) error {
+	if err := os.Chtimes(s.entryPath(key), at, at); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to record cache access: %w", err)
+	}
+	return nil
+}
+
+// Delete implements Store
+func (s *DirStore) Delete(key string) error {
+	if _, err := os.Stat(s.entryPath(key)); os.IsNotExist(err) {
+		return nil
+	}
+
+	lock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if err := os.Remove(s.entryPath(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// List implements Store. Only the metadata line of each entry is read.
+func (s *DirStore) List() ([]Info, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var infos []Info
+	for _, de := range dirEntries {
+		name := de.Name()
+		if filepath.Ext(name) != entryExt {
+			continue
+		}
+
+		meta, size, err := readHeader(filepath.Join(s.dir, name))
+		if err != nil {
+			continue
+		}
+
+		infos = append(infos, Info{
+			Key:           strings.TrimSuffix(name, entryExt),
+			CacheMetadata: meta,
+			Size:          size,
+		})
+	}
+
+	return infos, nil
+}
+
+// readHeader returns the metadata of the entry file at path and the size of
+// its data. LastAccess is the file's modification time if that is later.
+func readHeader(path string) (CacheMetadata, int64, error) {
+	var meta CacheMetadata
+
+	f, err := os.Open(path)
+	if err != nil {
+		return meta, 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return meta, 0, err
+	}
+
+	header, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil {
+		if err == io.EOF {
+			err = errCorruptEntry
+		}
+		return meta, 0, err
+	}
+	if err := json.Unmarshal(header, &meta); err != nil {
+		return meta, 0, errCorruptEntry
+	}
+	meta.LastAccess = max(meta.LastAccess, stat.ModTime().Unix())
+
+	return meta, stat.Size() - int64(len(header)), nil
+}
+
+// Clear implements Store. It also removes files left by interrupted writes
+// and by the former .json/.meta layout.
+func (s *DirStore) Clear() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	lock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case entryExt, tempExt, ".json", ".meta":
+			_ = os.Remove(filepath.Join(s.dir, entry.Name()))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDirStoreEntryFormat(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDirStore(dir)
+
+	if err := s.Put("key", []byte("line one\nline two"), CacheMetadata{CreatedAt: 1, TTL: 60, ETag: `"v1"`}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	if strings.Join(names, ",") != "entries.lock,key.entry" {
+		t.Errorf("Expected a single entry file beside the lock, got %v", names)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "key.entry"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"created_at":1,"ttl":60,"etag":"\"v1\""}` + "\nline one\nline two"
+	if string(raw) != want {
+		t.Errorf("Entry file = %q, want %q", raw, want)
+	}
+
+	data, meta, ok := s.Get("key")
+	if !ok || string(data) != "line one\nline two" || meta.ETag != `"v1"` {
+		t.Errorf("Get() = %q, %+v, %v", data, meta, ok)
+	}
+
+	infos, err := s.List()
+	if err != nil || len(infos) != 1 || infos[0].Size != int64(len("line one\nline two")) {
+		t.Errorf("List() = %+v, %v; want one entry sized to its data", infos, err)
+	}
+}
+
+func TestDirStoreConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+
+	// Each goroutine uses its own store, as separate processes would, and
+	// writes entries whose data matches their ETag
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			s := NewDirStore(dir)
+			for i := 0; i < 25; i++ {
+				version := fmt.Sprintf("%d-%d", w, i)
+				if err := s.Put("shared", []byte(version), CacheMetadata{ETag: version}); err != nil {
+					t.Errorf("Put() error = %v", err)
+					return
+				}
+				if data, meta, ok := s.Get("shared"); ok && string(data) != meta.ETag {
+					t.Errorf("Get() paired data %q with metadata for %q", data, meta.ETag)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	leftovers, _ := filepath.Glob(filepath.Join(dir, "*"+tempExt))
+	if len(leftovers) != 0 {
+		t.Errorf("Expected no temporary files, got %v", leftovers)
+	}
+}
+
+func TestDirStoreRemovesLegacyFiles(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDirStore(dir)
+
+	for _, name := range []string{"a.json", "a.meta", "b.json", "b.meta", "c.1234" + tempExt} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if infos, err := s.List(); err != nil || len(infos) != 0 {
+		t.Errorf("List() = %+v, %v; want legacy and temporary files ignored", infos, err)
+	}
+
+	// Writing a key replaces its legacy files
+	if err := s.Put("a", []byte("{}"), CacheMetadata{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.json", "a.meta"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed by Put", name)
+		}
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		if f.Name() != dirLockFile {
+			t.Errorf("Expected %s to be removed by Clear", f.Name())
+		}
+	}
+}
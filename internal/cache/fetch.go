@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grokipedia/cli/internal/api"
+)
+
+// Fetch returns the value cached for req, calling fetch and caching its
+// result on a miss. fetch is given the validators to make its request
+// conditional on, which are zero unless an expired entry is being
+// revalidated, and returns those of the response it received. A fetch
+// failing with api.ErrNotModified restarts the entry's TTL instead of
+// downloading the body again. A nil cache always calls fetch.
+//
+// Within the StaleWhileRevalidate window an expired entry is returned at
+// once and refreshed in the background; see Wait. Within the StaleIfError
+// window it is returned, with a warning, when the refresh fails because the
+// API is unavailable.
+//
+// In offline mode fetch is never called: the stored entry is returned
+// whether or not it has expired, and a missing one is an
+// api.NotCachedError.
+func Fetch[T any](ctx context.Context, c *Cache, req Request, fetch FetchFunc[T]) (T, error) {
+	if c == nil {
+		result, _, err := fetch(ctx, api.Validators{})
+		return result, err
+	}
+
+	key := c.Key(req)
+	entry, found := c.Lookup(key)
+
+	var cached T
+	if found && json.Unmarshal(entry.Data, &cached) != nil {
+		entry, found = nil, false
+	}
+	if found && (!entry.Expired || c.opts.Offline) {
+		c.recordLookup(true)
+		return cached, nil
+	}
+	if c.opts.Offline {
+		c.recordLookup(false)
+		return cached, &api.NotCachedError{Request: req.Canonical()}
+	}
+
+	var staleness time.Duration
+	if found {
+		staleness = entry.Staleness(time.Now())
+	}
+
+	if found && withinWindow(staleness, c.opts.StaleWhileRevalidate) {
+		c.recordLookup(true)
+		bg, cancel := c.background(ctx)
+		c.pending.Add(1)
+		go func() {
+			defer c.pending.Done()
+			defer cancel()
+			_, _, _ = refresh(bg, c, req, entry, cached, fetch)
+		}()
+		return cached, nil
+	}
+
+	result, revalidated, err := refresh(ctx, c, req, entry, cached, fetch)
+	if err != nil && found && withinWindow(staleness, c.opts.StaleIfError) && api.IsUnavailable(err) {
+		c.recordLookup(true)
+		c.warnf("warning: %v; serving cached response that expired %s ago\n", err, staleness.Round(time.Second))
+		return cached, nil
+	}
+	c.recordLookup(revalidated)
+	return result, err
+}
+
+// background returns a context for work started on behalf of ctx that
+// outlives it: it carries the values of ctx but is cancelled only with
+// Options.Context. Call cancel once the work is done.
+func (c *Cache) background(ctx context.Context) (context.Context, context.CancelFunc) {
+	parent := c.opts.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	bg, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	unlink := context.AfterFunc(parent, cancel)
+	return bg, func() {
+		unlink()
+		cancel()
+	}
+}
+
+// FetchFunc fetches a response for Fetch, conditional on the validators it
+// is given, and returns the validators of the response
+type FetchFunc[T any] func(ctx context.Context, v api.Validators) (T, api.Validators, error)
+
+// withinWindow reports whether an entry that expired staleness ago may be
+// served under a stale window; a zero window disables the behaviour
+func withinWindow(staleness, window time.Duration) bool {
+	return window > 0 && staleness <= window
+}
+
+// refresh fetches req anew and caches the result. When entry is set its
+// validators make the request conditional, and a 304 reply restarts the
+// entry's TTL and returns cached with revalidated set.
+func refresh[T any](ctx context.Context, c *Cache, req Request, entry *Entry, cached T, fetch FetchFunc[T]) (result T, revalidated bool, err error) {
+	var sent api.Validators
+	if entry != nil {
+		sent = api.Validators{ETag: entry.ETag, LastModified: entry.LastModified}
+	}
+
+	result, validators, err := fetch(ctx, sent)
+	if errors.Is(err, api.ErrNotModified) && entry != nil {
+		_ = c.Refresh(c.Key(req))
+		return cached, true, nil
+	}
+	if err != nil {
+		return result, false, err
+	}
+
+	if data, err := json.Marshal(result); err == nil {
+		_ = c.SetEntry(c.Key(req), data, CacheMetadata{
+			Request:      req.Canonical(),
+			ETag:         validators.ETag,
+			LastModified: validators.LastModified,
+		})
+	}
+
+	return result, false, nil
+}
+
+// warnf writes a warning to the configured Warnings writer, if any
+func (c *Cache) warnf(format string, args ...interface{}) {
+	if c.opts.Warnings != nil {
+		fmt.Fprintf(c.opts.Warnings, format, args...)
+	}
+}
@@ -0,0 +1,343 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grokipedia/cli/internal/api"
+)
+
+// expire backdates the entry for key so that it has outlived its TTL
+func expire(t *testing.T, c *Cache, key string) {
+	t.Helper()
+
+	_, meta, ok := c.store.Get(key)
+	if !ok {
+		t.Fatalf("No cache entry for %s", key)
+	}
+	meta.CreatedAt -= int64(meta.TTL) + 10
+	if err := c.store.SetMetadata(key, meta); err != nil {
+		t.Fatalf("Failed to write metadata: %v", err)
+	}
+}
+
+// unconditional adapts f to a FetchFunc that ignores validators
+func unconditional[T any](f func(ctx context.Context) (T, error)) FetchFunc[T] {
+	return func(ctx context.Context, _ api.Validators) (T, api.Validators, error) {
+		result, err := f(ctx)
+		return result, api.Validators{}, err
+	}
+}
+
+func TestFetchRevalidatesExpiredEntry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"page": {"title": "Python", "slug": "Python"}, "found": true}`))
+	}))
+	defer server.Close()
+
+	client := api.NewClient(api.ClientOptions{BaseURL: server.URL, Timeout: 30})
+	tmpDir := t.TempDir()
+	c := New(tmpDir, 60)
+	req := Request{Endpoint: "/api/page", Params: map[string]interface{}{"slug": "Python"}}
+	key := c.Key(req)
+
+	fetch := func(ctx context.Context, v api.Validators) (*api.PageResponse, api.Validators, error) {
+		conditional := client.Conditional(v)
+		page, err := conditional.PageContext(ctx, "Python", false, true)
+		return page, conditional.Validators(), err
+	}
+
+	// Miss: full download, validators stored
+	page, err := Fetch(context.Background(), c, req, fetch)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if page.Page.Title != "Python" {
+		t.Errorf("Expected title Python, got %q", page.Page.Title)
+	}
+	entry, found := c.Lookup(key)
+	if !found || entry.ETag != `"v1"` {
+		t.Fatalf("Expected cached entry with ETag, got %+v", entry)
+	}
+
+	// Fresh hit: no request
+	if _, err := Fetch(context.Background(), c, req, fetch); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected fresh entry to be served from cache, got %d requests", requests)
+	}
+
+	// Expired: revalidated with a 304 and refreshed
+	expire(t, c, key)
+	page, err = Fetch(context.Background(), c, req, fetch)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected a conditional request, got %d requests", requests)
+	}
+	if page.Page.Title != "Python" {
+		t.Errorf("Expected cached title after 304, got %q", page.Page.Title)
+	}
+	if entry, found := c.Lookup(key); !found || entry.Expired {
+		t.Error("Expected 304 to refresh the entry")
+	}
+}
+
+func TestFetchDoesNotCacheErrors(t *testing.T) {
+	c := New(t.TempDir(), 60)
+	wantErr := &api.NotFoundError{Resource: "Missing"}
+
+	req := Request{Endpoint: "/api/page", Params: map[string]interface{}{"slug": "Missing"}}
+	_, err := Fetch(context.Background(), c, req, unconditional(func(ctx context.Context) (*api.PageResponse, error) {
+		return nil, wantErr
+	}))
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Expected NotFoundError, got %v", err)
+	}
+	if _, found := c.Lookup(c.Key(req)); found {
+		t.Error("Expected failed fetch not to be cached")
+	}
+}
+
+func TestFetchNilCache(t *testing.T) {
+	calls := 0
+	for i := 0; i < 2; i++ {
+		_, err := Fetch(context.Background(), nil, Request{}, unconditional(func(ctx context.Context) (int, error) {
+			calls++
+			return 1, nil
+		}))
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected every call to reach fetch without a cache, got %d", calls)
+	}
+}
+
+func TestLookupKeepsRevalidatableEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	c := New(tmpDir, 60)
+
+	if err := c.SetEntry("validated", []byte(`{}`), CacheMetadata{ETag: `"etag"`}); err != nil {
+		t.Fatalf("SetEntry() error = %v", err)
+	}
+	if err := c.Set("plain", []byte(`{}`)); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	expire(t, c, "validated")
+	expire(t, c, "plain")
+
+	if _, found := c.Get("validated"); found {
+		t.Error("Get() returned an expired entry")
+	}
+	if entry, found := c.Lookup("validated"); !found || !entry.Expired {
+		t.Error("Expected expired entry with validators to be kept for revalidation")
+	}
+	if _, found := c.Lookup("plain"); found {
+		t.Error("Expected expired entry without validators to be deleted")
+	}
+
+	before := time.Now().Unix()
+	if err := c.Refresh("validated"); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	entry, found := c.Lookup("validated")
+	if !found || entry.Expired || entry.CreatedAt < before || entry.ETag != `"etag"` {
+		t.Errorf("Expected refreshed entry keeping its ETag, got %+v", entry)
+	}
+}
+
+func TestFetchStaleIfError(t *testing.T) {
+	tmpDir := t.TempDir()
+	var warnings bytes.Buffer
+	c := NewWithOptions(tmpDir, 60, Options{StaleIfError: time.Hour, Warnings: &warnings})
+	req := Request{Endpoint: "/api/test"}
+	key := c.Key(req)
+
+	if err := c.Set(key, []byte(`"cached"`)); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	expire(t, c, key)
+
+	got, err := Fetch(context.Background(), c, req, unconditional(func(ctx context.Context) (string, error) {
+		return "", &api.ServerError{StatusCode: http.StatusServiceUnavailable}
+	}))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v, want stale value", err)
+	}
+	if got != "cached" {
+		t.Errorf("Fetch() = %q, want %q", got, "cached")
+	}
+	if !strings.Contains(warnings.String(), "server error: 503") {
+		t.Errorf("Expected warning naming the error, got %q", warnings.String())
+	}
+
+	// Errors that are not about availability are returned as-is
+	_, err = Fetch(context.Background(), c, req, unconditional(func(ctx context.Context) (string, error) {
+		return "", &api.NotFoundError{Resource: "test"}
+	}))
+	var notFound *api.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("Expected NotFoundError, got %v", err)
+	}
+}
+
+func TestFetchStaleIfErrorWindowExceeded(t *testing.T) {
+	tmpDir := t.TempDir()
+	c := NewWithOptions(tmpDir, 60, Options{StaleIfError: time.Second})
+	req := Request{Endpoint: "/api/test"}
+	key := c.Key(req)
+
+	if err := c.SetEntry(key, []byte(`"cached"`), CacheMetadata{ETag: `"v1"`}); err != nil {
+		t.Fatalf("SetEntry() error = %v", err)
+	}
+	expire(t, c, key)
+
+	_, err := Fetch(context.Background(), c, req, unconditional(func(ctx context.Context) (string, error) {
+		return "", &api.NetworkError{Message: "connection refused"}
+	}))
+	var networkErr *api.NetworkError
+	if !errors.As(err, &networkErr) {
+		t.Errorf("Expected NetworkError past the stale window, got %v", err)
+	}
+}
+
+func TestFetchStaleWhileRevalidate(t *testing.T) {
+	tmpDir := t.TempDir()
+	c := NewWithOptions(tmpDir, 60, Options{StaleWhileRevalidate: time.Hour})
+	req := Request{Endpoint: "/api/test"}
+	key := c.Key(req)
+
+	if err := c.Set(key, []byte(`"old"`)); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	expire(t, c, key)
+
+	release := make(chan struct{})
+	got, err := Fetch(context.Background(), c, req, unconditional(func(ctx context.Context) (string, error) {
+		<-release
+		return "new", nil
+	}))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got != "old" {
+		t.Errorf("Fetch() = %q, want stale %q served immediately", got, "old")
+	}
+
+	close(release)
+	c.Wait()
+
+	data, found := c.Get(key)
+	if !found || string(data) != `"new"` {
+		t.Errorf("Expected background refresh to store the new value, got %q (found=%v)", data, found)
+	}
+}
+
+func TestFetchRevalidationCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewWithOptions(t.TempDir(), 60, Options{StaleWhileRevalidate: time.Hour, Context: ctx})
+	req := Request{Endpoint: "/api/test"}
+	key := c.Key(req)
+
+	if err := c.Set(key, []byte(`"old"`)); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	expire(t, c, key)
+
+	// The caller's context ending does not stop the revalidation
+	callerCtx, callerCancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	got, err := Fetch(callerCtx, c, req, unconditional(func(ctx context.Context) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	}))
+	callerCancel()
+	if err != nil || got != "old" {
+		t.Fatalf("Fetch() = %q, %v; want stale %q", got, err, "old")
+	}
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		c.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Expected Wait to block while the revalidation runs")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected cancelling Options.Context to stop the revalidation")
+	}
+
+	if data, _ := c.Get(key); data != nil {
+		t.Errorf("Expected the cancelled revalidation not to store anything, got %q", data)
+	}
+}
+
+func TestFetchOffline(t *testing.T) {
+	dir := t.TempDir()
+	req := Request{Endpoint: "/api/constants"}
+
+	online := New(dir, 60)
+	if _, err := Fetch(context.Background(), online, req, unconditional(func(ctx context.Context) (string, error) {
+		return "cached", nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	expire(t, online, online.Key(req))
+
+	c := NewWithOptions(dir, 60, Options{Offline: true})
+	calls := 0
+	fetch := unconditional(func(ctx context.Context) (string, error) {
+		calls++
+		return "fresh", nil
+	})
+
+	// Expired entries are served as they are
+	got, err := Fetch(context.Background(), c, req, fetch)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got != "cached" {
+		t.Errorf("Fetch() = %q, want cached value", got)
+	}
+
+	// Missing entries fail without calling the API
+	_, err = Fetch(context.Background(), c, Request{Endpoint: "/api/page", Params: map[string]interface{}{"slug": "Go"}}, fetch)
+	var notCached *api.NotCachedError
+	if !errors.As(err, &notCached) || api.GetExitCode(err) != api.ExitNotCached {
+		t.Errorf("Expected not cached error, got %v", err)
+	}
+	if notCached != nil && notCached.Request != "/api/page?slug=Go" {
+		t.Errorf("NotCachedError.Request = %q, want /api/page?slug=Go", notCached.Request)
+	}
+
+	if calls != 0 {
+		t.Errorf("Expected no API calls offline, got %d", calls)
+	}
+}
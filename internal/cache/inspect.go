@@ -0,0 +1,227 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/grokipedia/cli/internal/filelock"
+)
+
+// statsFile holds the hit and miss counters shared by every process using
+// the cache directory
+const statsFile = "stats.lock"
+
+// Info describes a stored cache entry for inspection
+type Info struct {
+	Key string `json:"key"`
+	CacheMetadata
+	Size int64 `json:"size"` // bytes of stored data, after compression
+}
+
+// CreatedTime returns when the entry was stored
+func (i Info) CreatedTime() time.Time {
+	return time.Unix(i.CreatedAt, 0)
+}
+
+// LastAccessTime returns when the entry was last read or written
+func (i Info) LastAccessTime() time.Time {
+	if i.LastAccess == 0 {
+		return i.CreatedTime()
+	}
+	return time.Unix(i.LastAccess, 0)
+}
+
+// ExpiresAt returns when the entry's TTL runs out
+func (i Info) ExpiresAt() time.Time {
+	return time.Unix(i.CreatedAt+int64(i.TTL), 0)
+}
+
+// Filter selects entries by age, endpoint and page slug. Zero fields match
+// everything; set fields must all match.
+type Filter struct {
+	// OlderThan matches entries stored longer ago than this
+	OlderThan time.Duration
+	// Endpoint matches entries for this API path
+	Endpoint string
+	// Slug matches entries whose request has this slug parameter
+	Slug string
+}
+
+// IsZero reports whether the filter matches every entry
+func (f Filter) IsZero() bool {
+	return f == Filter{}
+}
+
+// Matches reports whether info satisfies every set field of the filter
+func (f Filter) Matches(info Info, now time.Time) bool {
+	if f.OlderThan > 0 && now.Sub(info.CreatedTime()) <= f.OlderThan {
+		return false
+	}
+	if f.Endpoint != "" && info.Endpoint() != f.Endpoint {
+		return false
+	}
+	if f.Slug != "" && info.Params().Get("slug") != f.Slug {
+		return false
+	}
+	return true
+}
+
+// Stats summarizes the cache contents and how often lookups were served
+// from it
+type Stats struct {
+	Entries   int   `json:"entries"`
+	Expired   int   `json:"expired"`
+	TotalSize int64 `json:"total_size"`
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+}
+
+// HitRatio returns the fraction of lookups served from the cache
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// counters is the on-disk form of the hit and miss counts
+type counters struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// Entries returns every stored entry, oldest first. Expired entries are
+// included and nothing is deleted.
+func (c *Cache) Entries() ([]Info, error) {
+	c.migrate(false)
+
+	infos, err := c.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].CreatedAt != infos[j].CreatedAt {
+			return infos[i].CreatedAt < infos[j].CreatedAt
+		}
+		return infos[i].Key < infos[j].Key
+	})
+
+	return infos, nil
+}
+
+// Data returns the stored data for key regardless of expiry
+func (c *Cache) Data(key string) ([]byte, error) {
+	data, meta, ok := c.store.Get(key)
+	if !ok {
+		return nil, fmt.Errorf("failed to read cache entry %s", key)
+	}
+	return decompress(meta.Encoding, data)
+}
+
+// Purge deletes every entry matching f and reports how many were deleted.
+// A zero filter deletes every entry. Entries that cannot be deleted are
+// skipped, and their errors are returned together with the count.
+func (c *Cache) Purge(f Filter) (int, error) {
+	infos, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	purged := 0
+	var errs []error
+	for _, info := range infos {
+		if !f.Matches(info, now) {
+			continue
+		}
+		if err := c.Delete(info.Key); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		purged++
+	}
+
+	if len(errs) > 0 {
+		return purged, fmt.Errorf("failed to delete %d of %d matching entries: %w", len(errs), purged+len(errs), errors.Join(errs...))
+	}
+	return purged, nil
+}
+
+// Stats returns the current cache contents summary and lookup counters
+func (c *Cache) Stats() (Stats, error) {
+	infos, err := c.Entries()
+	if err != nil {
+		return Stats{}, err
+	}
+
+	now := time.Now()
+	stats := Stats{Entries: len(infos)}
+	for _, info := range infos {
+		stats.TotalSize += info.Size
+		if now.After(info.ExpiresAt()) {
+			stats.Expired++
+		}
+	}
+
+	counts, err := c.updateCounters(nil)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats.Hits = counts.Hits + c.hits.Load()
+	stats.Misses = counts.Misses + c.misses.Load()
+
+	return stats, nil
+}
+
+// recordLookup counts a Fetch served from the cache (hit) or from the API
+// (miss). The counts are kept in memory until Wait writes them.
+func (c *Cache) recordLookup(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// flushCounters adds the lookups counted in memory to the shared counters.
+// Failures to record are ignored.
+func (c *Cache) flushCounters() {
+	hits, misses := c.hits.Swap(0), c.misses.Swap(0)
+	if hits == 0 && misses == 0 {
+		return
+	}
+	_, _ = c.updateCounters(func(cs *counters) {
+		cs.Hits += hits
+		cs.Misses += misses
+	})
+}
+
+// updateCounters applies update to the shared counters while holding the
+// stats file lock and returns the result. A nil update only reads them.
+func (c *Cache) updateCounters(update func(*counters)) (counters, error) {
+	var cs counters
+
+	path := filepath.Join(c.dir, statsFile)
+	if _, err := os.Stat(path); update == nil && os.IsNotExist(err) {
+		return cs, nil
+	}
+
+	err := filelock.Update(path, func(data []byte) ([]byte, error) {
+		// A missing or corrupt stats file starts from zero
+		if len(data) > 0 {
+			_ = json.Unmarshal(data, &cs)
+		}
+		if update == nil {
+			return nil, nil
+		}
+		update(&cs)
+		return json.Marshal(cs)
+	})
+	return cs, err
+}
@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntries(t *testing.T) {
+	c := New(t.TempDir(), 3600)
+
+	page := Request{Endpoint: "/api/page", Params: map[string]interface{}{"slug": "Go", "includeContent": true}}
+	search := Request{Endpoint: "/api/full-text-search", Params: map[string]interface{}{"q": "go"}}
+
+	now := time.Now().Unix()
+	if err := c.SetEntry(c.Key(page), []byte(`{"found":true}`), CacheMetadata{Request: page.Canonical(), CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetEntry(c.Key(search), []byte(`{}`), CacheMetadata{Request: search.Canonical(), CreatedAt: now - 60}); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := c.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(infos))
+	}
+
+	// Oldest first
+	if infos[0].Key != c.Key(search) || infos[1].Key != c.Key(page) {
+		t.Errorf("Expected entries oldest first, got %s, %s", infos[0].Key, infos[1].Key)
+	}
+	if got := infos[1].Endpoint(); got != "/api/page" {
+		t.Errorf("Endpoint() = %q, want /api/page", got)
+	}
+	if got := infos[1].Params().Get("slug"); got != "Go" {
+		t.Errorf("Params().Get(slug) = %q, want Go", got)
+	}
+	if infos[1].Size != int64(len(`{"found":true}`)) {
+		t.Errorf("Size = %d, want %d", infos[1].Size, len(`{"found":true}`))
+	}
+}
+
+func TestEntriesMissingDirectory(t *testing.T) {
+	c := New(t.TempDir()+"/missing", 3600)
+
+	infos, err := c.Entries()
+	if err != nil || infos != nil {
+		t.Errorf("Entries() = %v, %v; want nil, nil", infos, err)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	c := New(t.TempDir(), 3600)
+
+	for _, slug := range []string{"Go", "Rust", "Zig"} {
+		req := Request{Endpoint: "/api/page", Params: map[string]interface{}{"slug": slug}}
+		if err := c.SetEntry(c.Key(req), []byte(`{}`), CacheMetadata{Request: req.Canonical()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purged, err := c.Purge(Filter{Endpoint: "/api/page", Slug: "Rust"})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("Expected 1 entry purged, got %d", purged)
+	}
+
+	infos, _ := c.Entries()
+	if len(infos) != 2 {
+		t.Errorf("Expected 2 entries to remain, got %d", len(infos))
+	}
+	for _, info := range infos {
+		if info.Params().Get("slug") == "Rust" {
+			t.Errorf("Expected the Rust entry to be purged")
+		}
+	}
+}
+
+// failingDeleteStore is a Store whose Delete fails for the keys in fail
+type failingDeleteStore struct {
+	Store
+	fail map[string]bool
+}
+
+func (s failingDeleteStore) Delete(key string) error {
+	if s.fail[key] {
+		return errors.New("permission denied")
+	}
+	return s.Store.Delete(key)
+}
+
+func TestPurgeCountsOnlyDeletedEntries(t *testing.T) {
+	dir := t.TempDir()
+	store := failingDeleteStore{Store: NewDirStore(dir), fail: map[string]bool{}}
+	c := NewWithOptions(dir, 3600, Options{Store: store})
+
+	for _, slug := range []string{"Go", "Rust", "Zig"} {
+		req := Request{Endpoint: "/api/page", Params: map[string]interface{}{"slug": slug}}
+		if err := c.SetEntry(c.Key(req), []byte(`{}`), CacheMetadata{Request: req.Canonical()}); err != nil {
+			t.Fatal(err)
+		}
+		if slug == "Rust" {
+			store.fail[c.Key(req)] = true
+		}
+	}
+
+	purged, err := c.Purge(Filter{})
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected the failed delete to be reported, got %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("Expected 2 entries purged, got %d", purged)
+	}
+
+	infos, _ := c.Entries()
+	if len(infos) != 1 {
+		t.Errorf("Expected the entry that failed to delete to remain, got %d entries", len(infos))
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	now := time.Now()
+	info := Info{CacheMetadata: CacheMetadata{
+		Request:   Request{Endpoint: "/api/page", Params: map[string]interface{}{"slug": "Go"}}.Canonical(),
+		CreatedAt: now.Add(-2 * time.Hour).Unix(),
+	}}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{name: "zero filter", filter: Filter{}, want: true},
+		{name: "older than", filter: Filter{OlderThan: time.Hour}, want: true},
+		{name: "too recent", filter: Filter{OlderThan: 3 * time.Hour}, want: false},
+		{name: "endpoint", filter: Filter{Endpoint: "/api/page"}, want: true},
+		{name: "other endpoint", filter: Filter{Endpoint: "/api/full-text-search"}, want: false},
+		{name: "slug", filter: Filter{Slug: "Go"}, want: true},
+		{name: "all fields must match", filter: Filter{Endpoint: "/api/page", Slug: "Rust"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(info, now); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, 3600)
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats != (Stats{}) {
+		t.Errorf("Expected zero stats for an empty cache, got %+v", stats)
+	}
+
+	req := Request{Endpoint: "/api/constants"}
+	fetch := unconditional(func(ctx context.Context) (string, error) { return "value", nil })
+	for i := 0; i < 3; i++ {
+		if _, err := Fetch(context.Background(), c, req, fetch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expire(t, c, c.Key(req))
+
+	// Counters are shared with other instances once written by Wait
+	if stats, _ := New(dir, 3600).Stats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("Expected counters to stay in memory until Wait, got %+v", stats)
+	}
+	c.Wait()
+	stats, err = New(dir, 3600).Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	want := Stats{Entries: 1, Expired: 1, TotalSize: int64(len(`"value"`)), Hits: 2, Misses: 1}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+	if got := stats.HitRatio(); got < 0.66 || got > 0.67 {
+		t.Errorf("HitRatio() = %v, want 2/3", got)
+	}
+}
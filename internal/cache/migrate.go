@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/grokipedia/cli/internal/filelock"
+)
+
+// SchemaVersion identifies the shape of cached responses. Bump it whenever
+// a change to the api model structs means data cached by earlier versions
+// no longer decodes into the same values; the next run clears the cache.
+const SchemaVersion = 1
+
+// versionFile records the schema version of the entries in the cache
+// directory
+const versionFile = "version.lock"
+
+// migrate clears entries written under an older SchemaVersion and records
+// the current one. Directories without a version file predate versioning
+// and are cleared too; a newer version is left alone so that an older CLI
+// does not destroy a newer one's entries, which its keys never match anyway.
+// A missing directory is only created when create is set. Failures are
+// ignored and retried on the next call.
+func (c *Cache) migrate(create bool) {
+	c.migrateMu.Lock()
+	defer c.migrateMu.Unlock()
+
+	if c.migrated {
+		return
+	}
+	if _, err := os.Stat(c.dir); !create && os.IsNotExist(err) {
+		return
+	}
+
+	err := filelock.Update(filepath.Join(c.dir, versionFile), func(data []byte) ([]byte, error) {
+		// A missing or corrupt version file is treated as version 0
+		version, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		if version >= SchemaVersion {
+			return nil, nil
+		}
+		if err := c.store.Clear(); err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(SchemaVersion) + "\n"), nil
+	})
+	c.migrated = err == nil
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string // contents of the version file; "-" for none
+		wantCleared bool
+		wantVersion string
+	}{
+		{name: "unversioned", version: "-", wantCleared: true, wantVersion: strconv.Itoa(SchemaVersion)},
+		{name: "older", version: strconv.Itoa(SchemaVersion - 1), wantCleared: true, wantVersion: strconv.Itoa(SchemaVersion)},
+		{name: "corrupt", version: "garbage", wantCleared: true, wantVersion: strconv.Itoa(SchemaVersion)},
+		{name: "current", version: strconv.Itoa(SchemaVersion), wantVersion: strconv.Itoa(SchemaVersion)},
+		{name: "newer", version: strconv.Itoa(SchemaVersion + 1), wantVersion: strconv.Itoa(SchemaVersion + 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+				dir := t.TempDir()
+
+				// Write an entry as an earlier run would have
+				earlier := newCache(dir, 3600)
+				if err := earlier.Set("old", []byte(`{}`)); err != nil {
+					t.Fatal(err)
+				}
+				if err := earlier.Close(); err != nil {
+					t.Fatal(err)
+				}
+				path := filepath.Join(dir, versionFile)
+				if err := os.Remove(path); err != nil {
+					t.Fatal(err)
+				}
+				if tt.version != "-" {
+					if err := os.WriteFile(path, []byte(tt.version), 0600); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				c := newCache(dir, 3600)
+				_, found := c.Get("old")
+				if found == tt.wantCleared {
+					t.Errorf("Get() found = %v, want %v", found, !tt.wantCleared)
+				}
+
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := strings.TrimSpace(string(data)); got != tt.wantVersion {
+					t.Errorf("Version file = %q, want %s", data, tt.wantVersion)
+				}
+
+				// Entries written after the migration survive later runs
+				if err := c.Set("new", []byte(`{}`)); err != nil {
+					t.Fatal(err)
+				}
+				if err := c.Close(); err != nil {
+					t.Fatal(err)
+				}
+				if _, found := newCache(dir, 3600).Get("new"); !found {
+					t.Error("Expected entry written after migration to be kept")
+				}
+			})
+		})
+	}
+}
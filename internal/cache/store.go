@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Store persists cache entries. Cache layers expiry, revalidation, eviction
+// and statistics on top of a Store, so implementations only need to store,
+// retrieve and enumerate entries.
+type Store interface {
+	// Get returns the data and metadata stored for key. ok is false if
+	// there is no entry or it cannot be read.
+	Get(key string) (data []byte, meta CacheMetadata, ok bool)
+	// Put stores data and metadata for key, replacing any existing entry
+	Put(key string, data []byte, meta CacheMetadata) error
+	// SetMetadata replaces the metadata of an existing entry
+	SetMetadata(key string, meta CacheMetadata) error
+	// Touch records that the entry for key was accessed at the given time,
+	// without rewriting it. List reports the later of that time and the
+	// stored LastAccess.
+	Touch(key string, at time.Time) error
+	// Delete removes the entry for key, if any
+	Delete(key string) error
+	// List returns every stored entry in no particular order
+	List() ([]Info, error)
+	// Clear removes every entry
+	Clear() error
+}
+
+// Cache backends selectable with NewStore
+const (
+	// BackendDir stores each entry as files in the cache directory
+	BackendDir = "dir"
+	// BackendBolt stores every entry in a single embedded database file
+	BackendBolt = "bolt"
+)
+
+// Backends lists the backend names accepted by NewStore
+var Backends = []string{BackendDir, BackendBolt}
+
+// NewStore returns the store for the named backend, keeping its files in
+// dir. An empty name selects BackendDir.
+func NewStore(backend, dir string) (Store, error) {
+	switch backend {
+	case "", BackendDir:
+		return NewDirStore(dir), nil
+	case BackendBolt:
+		return NewBoltStore(filepath.Join(dir, boltFile)), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend '%s'; allowed: %v", backend, Backends)
+	}
+}
@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newBackendCache returns a cache in dir using the named backend
+func newBackendCache(tb testing.TB, backend, dir string, ttl int) *Cache {
+	tb.Helper()
+
+	store, err := NewStore(backend, dir)
+	if err != nil {
+		tb.Fatalf("NewStore(%q) error = %v", backend, err)
+	}
+	c := NewWithOptions(dir, ttl, Options{Store: store})
+	tb.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+// forEachBackend runs test once per backend. newCache returns a cache in
+// dir backed by the backend under test.
+func forEachBackend(t *testing.T, test func(t *testing.T, newCache func(dir string, ttl int) *Cache)) {
+	for _, backend := range Backends {
+		t.Run(backend, func(t *testing.T) {
+			test(t, func(dir string, ttl int) *Cache {
+				return newBackendCache(t, backend, dir, ttl)
+			})
+		})
+	}
+}
+
+// benchForEachBackend runs bench once per backend
+func benchForEachBackend(b *testing.B, bench func(b *testing.B, newCache func(dir string, ttl int) *Cache)) {
+	for _, backend := range Backends {
+		b.Run(backend, func(b *testing.B) {
+			bench(b, func(dir string, ttl int) *Cache {
+				return newBackendCache(b, backend, dir, ttl)
+			})
+		})
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		backend string
+		want    Store
+		wantErr bool
+	}{
+		{backend: "", want: NewDirStore(dir)},
+		{backend: BackendDir, want: NewDirStore(dir)},
+		{backend: BackendBolt, want: NewBoltStore(filepath.Join(dir, boltFile))},
+		{backend: "sqlite", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			got, err := NewStore(tt.backend, dir)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error for unknown backend")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewStore() error = %v", err)
+			}
+			switch want := tt.want.(type) {
+			case *DirStore:
+				if s, ok := got.(*DirStore); !ok || *s != *want {
+					t.Errorf("NewStore() = %#v, want %#v", got, want)
+				}
+			case *BoltStore:
+				if s, ok := got.(*BoltStore); !ok || s.path != want.path {
+					t.Errorf("NewStore() = %#v, want %#v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBoltStoreSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	c := newBackendCache(t, BackendBolt, dir, 3600)
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Set(key, []byte(`{}`)); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		if f.Name() != versionFile {
+			names = append(names, f.Name())
+		}
+	}
+	if len(names) != 1 || names[0] != boltFile {
+		t.Errorf("Expected only %s besides the version file in the cache directory, got %v", boltFile, names)
+	}
+}
+
+func TestBoltStoreReadsDoNotCreateDatabase(t *testing.T) {
+	dir := t.TempDir()
+	c := newBackendCache(t, BackendBolt, dir, 3600)
+
+	if _, found := c.Get("missing"); found {
+		t.Error("Get() returned found=true on an empty store")
+	}
+	if infos, err := c.Entries(); err != nil || len(infos) != 0 {
+		t.Errorf("Entries() = %v, %v; want empty", infos, err)
+	}
+	if err := c.Clear(); err != nil {
+		t.Errorf("Clear() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, boltFile)); !os.IsNotExist(err) {
+		t.Error("Expected reads not to create the database file")
+	}
+}
+
+func TestBoltStoreSharedBetweenStores(t *testing.T) {
+	// Each store stands for another process: the database must not stay
+	// locked between their transactions
+	path := filepath.Join(t.TempDir(), boltFile)
+	first, second := NewBoltStore(path), NewBoltStore(path)
+
+	if err := first.Put("a", []byte(`{}`), CacheMetadata{}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, _, ok := first.Get("a"); !ok {
+		t.Fatal("Expected the entry to be stored")
+	}
+	if err := second.Put("b", []byte(`{}`), CacheMetadata{}); err != nil {
+		t.Fatalf("Put() from a second store error = %v", err)
+	}
+	if _, _, ok := first.Get("b"); !ok {
+		t.Error("Expected the first store to read the entry written by the second")
+	}
+	if _, _, ok := second.Get("a"); !ok {
+		t.Error("Expected the second store to read the entry written by the first")
+	}
+}
+
+func TestStoreTouch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newCache func(dir string, ttl int) *Cache) {
+		c := newCache(t.TempDir(), 3600)
+		written := time.Now().Add(-time.Hour).Unix()
+		if err := c.SetEntry("key", []byte(`{}`), CacheMetadata{CreatedAt: written}); err != nil {
+			t.Fatalf("SetEntry() error = %v", err)
+		}
+
+		at := time.Now().Truncate(time.Second)
+		if err := c.store.Touch("key", at); err != nil {
+			t.Fatalf("Touch() error = %v", err)
+		}
+		if err := c.store.Touch("missing", at); err != nil {
+			t.Errorf("Touch() of a missing entry error = %v", err)
+		}
+
+		infos, err := c.store.List()
+		if err != nil || len(infos) != 1 {
+			t.Fatalf("List() = %v, %v; want one entry", infos, err)
+		}
+		if infos[0].LastAccess != at.Unix() || infos[0].CreatedAt != written {
+			t.Errorf("Expected LastAccess %d keeping CreatedAt, got %+v", at.Unix(), infos[0].CacheMetadata)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/grokipedia/cli/internal/api"
+)
+
+// DefaultWarmWorkers is the number of pages WarmPages fetches at once when
+// WarmOptions.Workers is not set
+const DefaultWarmWorkers = 4
+
+// PageRequest returns the request a page lookup for slug is cached under
+func PageRequest(slug string, includeContent, validateLinks bool) Request {
+	return Request{Endpoint: api.EndpointPage, Params: map[string]interface{}{
+		"slug":           slug,
+		"includeContent": includeContent,
+		"validateLinks":  validateLinks,
+	}}
+}
+
+// WarmOptions configures WarmPages
+type WarmOptions struct {
+	// Workers bounds how many pages are fetched at once; zero uses
+	// DefaultWarmWorkers
+	Workers int
+	// Progress, if set, receives a line as each slug finishes
+	Progress io.Writer
+}
+
+// WarmFailure records a slug that could not be warmed
+type WarmFailure struct {
+	Slug string
+	Err  error
+}
+
+// WarmPages fetches the page for every slug, both with and without content,
+// into the cache so that the page command can later answer from it. Pages
+// already cached and fresh are not fetched again. Requests go through
+// client, so its retry policy and rate limit apply. The failures are
+// returned in the order of slugs; cancelling ctx fails the remaining slugs.
+func WarmPages(ctx context.Context, c *Cache, client api.GrokipediaAPI, slugs []string, opts WarmOptions) []WarmFailure {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWarmWorkers
+	}
+
+	cc := NewCachedClient(client, c)
+	errs := make([]error, len(slugs))
+	jobs := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex // guards finished and writes to Progress
+		finished int
+	)
+	for w := 0; w < min(workers, len(slugs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = warmPage(ctx, cc, slugs[i])
+
+				mu.Lock()
+				finished++
+				if opts.Progress != nil {
+					status := "ok"
+					if errs[i] != nil {
+						status = "failed"
+					}
+					fmt.Fprintf(opts.Progress, "[%d/%d] %s %s\n", finished, len(slugs), slugs[i], status)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range slugs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var failures []WarmFailure
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, WarmFailure{Slug: slugs[i], Err: err})
+		}
+	}
+	return failures
+}
+
+// warmPage caches the page for slug without and then with content, using
+// the link validation the page command defaults to
+func warmPage(ctx context.Context, cc *CachedClient, slug string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, includeContent := range []bool{false, true} {
+		if _, err := cc.PageContext(ctx, slug, includeContent, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
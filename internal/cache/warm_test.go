@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/apitest"
+)
+
+func TestWarmPages(t *testing.T) {
+	fake := apitest.New()
+	fake.Pages["Go"] = api.PageData{Title: "Go", Slug: "Go", Content: "Go is a language."}
+	fake.Pages["Rust"] = api.PageData{Title: "Rust", Slug: "Rust", Content: "Rust is a language."}
+
+	c := New(t.TempDir(), 3600)
+	var progress bytes.Buffer
+
+	failures := WarmPages(context.Background(), c, fake, []string{"Go", "Missing", "Rust"}, WarmOptions{Progress: &progress})
+
+	if len(failures) != 1 || failures[0].Slug != "Missing" || api.GetExitCode(failures[0].Err) != api.ExitNotFound {
+		t.Errorf("Expected only Missing to fail as not found, got %+v", failures)
+	}
+	if lines := strings.Count(progress.String(), "\n"); lines != 3 {
+		t.Errorf("Expected a progress line per slug, got %q", progress.String())
+	}
+
+	// Both variants the page command asks for are cached
+	for _, slug := range []string{"Go", "Rust"} {
+		for _, content := range []bool{false, true} {
+			if _, found := c.Get(c.Key(PageRequest(slug, content, true))); !found {
+				t.Errorf("Expected %s (content=%v) to be cached", slug, content)
+			}
+		}
+	}
+
+	// Warming again is served from the cache
+	calls := fake.Calls(apitest.MethodPage)
+	WarmPages(context.Background(), c, fake, []string{"Go", "Rust"}, WarmOptions{})
+	if got := fake.Calls(apitest.MethodPage); got != calls {
+		t.Errorf("Expected fresh pages not to be fetched again, got %d more calls", got-calls)
+	}
+}
+
+func TestWarmPagesBoundsWorkers(t *testing.T) {
+	var (
+		mu               sync.Mutex
+		running, maxSeen int
+	)
+	fake := apitest.New()
+	fake.PageFunc = func(ctx context.Context, slug string, includeContent, validateLinks bool) (*api.PageResponse, error) {
+		mu.Lock()
+		running++
+		maxSeen = max(maxSeen, running)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return &api.PageResponse{Page: api.PageData{Slug: slug}, Found: true}, nil
+	}
+
+	slugs := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	failures := WarmPages(context.Background(), New(t.TempDir(), 3600), fake, slugs, WarmOptions{Workers: 3})
+	if len(failures) != 0 {
+		t.Fatalf("Unexpected failures: %+v", failures)
+	}
+	if maxSeen > 3 {
+		t.Errorf("Expected at most 3 concurrent fetches, saw %d", maxSeen)
+	}
+	if got := fake.Calls(apitest.MethodPage); got != 2*len(slugs) {
+		t.Errorf("Expected %d page fetches, got %d", 2*len(slugs), got)
+	}
+}
+
+func TestWarmPagesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	failures := WarmPages(ctx, New(t.TempDir(), 3600), apitest.New(), []string{"Go", "Rust"}, WarmOptions{})
+	if len(failures) != 2 {
+		t.Errorf("Expected every slug to fail once cancelled, got %+v", failures)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -16,12 +17,37 @@ type Config struct {
 	Cache    CacheConfig    `mapstructure:"cache"`
 	Output   OutputConfig   `mapstructure:"output"`
 	Commands CommandsConfig `mapstructure:"commands"`
+
+	// Diagnostics written to stderr
+	Verbose bool `mapstructure:"verbose"`
+	Debug   bool `mapstructure:"debug"`
 }
 
 // APIConfig holds API-related configuration
 type APIConfig struct {
-	URL     string `mapstructure:"url"`
-	Timeout int    `mapstructure:"timeout"`
+	URL       string          `mapstructure:"url"`
+	Timeout   int             `mapstructure:"timeout"`
+	Retry     RetryConfig     `mapstructure:"retry"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+}
+
+// RetryConfig holds the API retry policy
+type RetryConfig struct {
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	BaseDelay   time.Duration `mapstructure:"base_delay"`
+	MaxDelay    time.Duration `mapstructure:"max_delay"`
+	Statuses    []int         `mapstructure:"statuses"` // empty uses the client defaults
+
+	// Errors names the transport errors to retry, from
+	// api.RetryableErrorNames, or "none"; empty uses the client defaults
+	Errors []string `mapstructure:"errors"`
+}
+
+// RateLimitConfig holds the client-side rate limit shared by all processes
+// using the same cache directory
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"` // 0 disables limiting
+	Burst             int     `mapstructure:"burst"`
 }
 
 // CacheConfig holds cache-related configuration
@@ -29,6 +55,27 @@ type CacheConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
 	TTL     int    `mapstructure:"ttl"`
 	Dir     string `mapstructure:"dir"`
+	Backend string `mapstructure:"backend"` // dir or bolt
+
+	// Compression applied to newly stored responses: none, gzip or zstd
+	Compression string `mapstructure:"compression"`
+
+	// Offline answers every request from the cache, ignoring expiry, and
+	// never calls the API
+	Offline bool `mapstructure:"offline"`
+
+	// TTLByEndpoint overrides TTL, in seconds, per endpoint name (search,
+	// page, typeahead, constants, edits, edits-by-slug)
+	TTLByEndpoint map[string]int `mapstructure:"ttl_by_endpoint"`
+
+	// Seconds past expiry during which a stale entry may still be served
+	StaleIfError         int `mapstructure:"stale_if_error"`
+	StaleWhileRevalidate int `mapstructure:"stale_while_revalidate"`
+
+	// Limits enforced by evicting the least recently used entries; zero
+	// means unlimited
+	MaxSize    int64 `mapstructure:"max_size"` // bytes
+	MaxEntries int   `mapstructure:"max_entries"`
 }
 
 // OutputConfig holds output-related configuration
@@ -60,12 +107,24 @@ type GlobalFlags struct {
 	APIURL     string
 	Timeout    int
 	NoCache    bool
+	Offline    bool
 	CacheDir   string
 	CacheTTL   int
 	Verbose    bool
 	Debug      bool
 	ConfigFile string
 	Color      string
+
+	// Retry overrides; zero values keep the configured policy
+	MaxAttempts   int
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	RetryStatuses []int
+	RetryErrors   []string
+
+	// Rate limit overrides; zero values keep the configured limit
+	RateLimit float64
+	RateBurst int
 }
 
 // Load loads configuration from file, environment, and flags
@@ -76,9 +135,13 @@ func Load(flags GlobalFlags) (*Config, error) {
 	// Set defaults
 	setDefaults(v)
 
-	// Set config file if provided
-	if flags.ConfigFile != "" {
-		v.SetConfigFile(expandPath(flags.ConfigFile))
+	// Set config file if provided, by flag or environment
+	configFile := flags.ConfigFile
+	if configFile == "" {
+		configFile = os.Getenv("GROKIPEDIA_CONFIG")
+	}
+	if configFile != "" {
+		v.SetConfigFile(expandPath(configFile))
 	} else {
 		// Default config location
 		configDir := getDefaultConfigDir()
@@ -107,9 +170,19 @@ func Load(flags GlobalFlags) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// GROKIPEDIA_NO_CACHE disables the cache whatever cache.enabled says
+	if v.GetBool("no_cache") {
+		cfg.Cache.Enabled = false
+	}
+
 	// Expand paths in config
 	cfg.Cache.Dir = expandPath(cfg.Cache.Dir)
 
+	// --cache-ttl and GROKIPEDIA_CACHE_TTL apply to every endpoint
+	if flags.CacheTTL != 0 || os.Getenv("GROKIPEDIA_CACHE_TTL") != "" {
+		cfg.Cache.TTLByEndpoint = nil
+	}
+
 	return &cfg, nil
 }
 
@@ -117,10 +190,22 @@ func Load(flags GlobalFlags) (*Config, error) {
 func setDefaults(v *viper.Viper) {
 	v.SetDefault("api.url", "https://grokipedia.com")
 	v.SetDefault("api.timeout", 30)
+	v.SetDefault("api.retry.max_attempts", 3)
+	v.SetDefault("api.retry.base_delay", "1s")
+	v.SetDefault("api.retry.max_delay", "30s")
+	v.SetDefault("api.rate_limit.requests_per_second", 0)
+	v.SetDefault("api.rate_limit.burst", 1)
 
 	v.SetDefault("cache.enabled", true)
 	v.SetDefault("cache.ttl", 604800) // 7 days
 	v.SetDefault("cache.dir", "~/.grokipedia/cache")
+	v.SetDefault("cache.backend", "dir")
+	v.SetDefault("cache.compression", "none")
+	v.SetDefault("cache.offline", false)
+	v.SetDefault("cache.stale_if_error", 0)
+	v.SetDefault("cache.stale_while_revalidate", 0)
+	v.SetDefault("cache.max_size", 0)
+	v.SetDefault("cache.max_entries", 0)
 
 	v.SetDefault("output.format", "table")
 	v.SetDefault("output.color", "auto")
@@ -141,10 +226,26 @@ func bindEnvVars(v *viper.Viper) {
 	// Explicit bindings for nested keys
 	_ = v.BindEnv("api.url", "GROKIPEDIA_API_URL")
 	_ = v.BindEnv("api.timeout", "GROKIPEDIA_TIMEOUT")
-	_ = v.BindEnv("cache.enabled", "GROKIPEDIA_NO_CACHE")
+	_ = v.BindEnv("api.retry.max_attempts", "GROKIPEDIA_MAX_ATTEMPTS")
+	_ = v.BindEnv("api.retry.base_delay", "GROKIPEDIA_RETRY_DELAY")
+	_ = v.BindEnv("api.retry.max_delay", "GROKIPEDIA_MAX_RETRY_DELAY")
+	_ = v.BindEnv("api.retry.statuses", "GROKIPEDIA_RETRY_STATUSES")
+	_ = v.BindEnv("api.retry.errors", "GROKIPEDIA_RETRY_ERRORS")
+	_ = v.BindEnv("api.rate_limit.requests_per_second", "GROKIPEDIA_RATE_LIMIT")
+	_ = v.BindEnv("api.rate_limit.burst", "GROKIPEDIA_RATE_BURST")
+	_ = v.BindEnv("no_cache", "GROKIPEDIA_NO_CACHE")
 	_ = v.BindEnv("cache.ttl", "GROKIPEDIA_CACHE_TTL")
 	_ = v.BindEnv("cache.dir", "GROKIPEDIA_CACHE_DIR")
+	_ = v.BindEnv("cache.backend", "GROKIPEDIA_CACHE_BACKEND")
+	_ = v.BindEnv("cache.compression", "GROKIPEDIA_CACHE_COMPRESSION")
+	_ = v.BindEnv("cache.offline", "GROKIPEDIA_OFFLINE")
+	_ = v.BindEnv("cache.stale_if_error", "GROKIPEDIA_CACHE_STALE_IF_ERROR")
+	_ = v.BindEnv("cache.stale_while_revalidate", "GROKIPEDIA_CACHE_STALE_WHILE_REVALIDATE")
+	_ = v.BindEnv("cache.max_size", "GROKIPEDIA_CACHE_MAX_SIZE")
+	_ = v.BindEnv("cache.max_entries", "GROKIPEDIA_CACHE_MAX_ENTRIES")
 	_ = v.BindEnv("output.color", "GROKIPEDIA_COLOR")
+	_ = v.BindEnv("verbose", "GROKIPEDIA_VERBOSE")
+	_ = v.BindEnv("debug", "GROKIPEDIA_DEBUG")
 }
 
 // applyFlags applies CLI flag values to viper
@@ -155,9 +256,33 @@ func applyFlags(v *viper.Viper, flags GlobalFlags) {
 	if flags.Timeout > 0 {
 		v.Set("api.timeout", flags.Timeout)
 	}
+	if flags.MaxAttempts > 0 {
+		v.Set("api.retry.max_attempts", flags.MaxAttempts)
+	}
+	if flags.RetryDelay > 0 {
+		v.Set("api.retry.base_delay", flags.RetryDelay)
+	}
+	if flags.MaxRetryDelay > 0 {
+		v.Set("api.retry.max_delay", flags.MaxRetryDelay)
+	}
+	if len(flags.RetryStatuses) > 0 {
+		v.Set("api.retry.statuses", flags.RetryStatuses)
+	}
+	if len(flags.RetryErrors) > 0 {
+		v.Set("api.retry.errors", flags.RetryErrors)
+	}
+	if flags.RateLimit > 0 {
+		v.Set("api.rate_limit.requests_per_second", flags.RateLimit)
+	}
+	if flags.RateBurst > 0 {
+		v.Set("api.rate_limit.burst", flags.RateBurst)
+	}
 	if flags.NoCache {
 		v.Set("cache.enabled", false)
 	}
+	if flags.Offline {
+		v.Set("cache.offline", true)
+	}
 	if flags.CacheDir != "" {
 		v.Set("cache.dir", flags.CacheDir)
 	}
@@ -167,6 +292,12 @@ func applyFlags(v *viper.Viper, flags GlobalFlags) {
 	if flags.Color != "" {
 		v.Set("output.color", flags.Color)
 	}
+	if flags.Verbose {
+		v.Set("verbose", true)
+	}
+	if flags.Debug {
+		v.Set("debug", true)
+	}
 }
 
 // getDefaultConfigDir returns the default configuration directory
@@ -206,6 +337,25 @@ func (c *Config) GetCacheDir() string {
 	return c.Cache.Dir
 }
 
+// GetStaleIfError returns how long past expiry a cache entry may be served
+// when the API is unavailable
+func (c *Config) GetStaleIfError() time.Duration {
+	return time.Duration(max(c.Cache.StaleIfError, 0)) * time.Second
+}
+
+// GetStaleWhileRevalidate returns how long past expiry a cache entry is
+// served while it is refreshed in the background
+func (c *Config) GetStaleWhileRevalidate() time.Duration {
+	return time.Duration(max(c.Cache.StaleWhileRevalidate, 0)) * time.Second
+}
+
+// GetRateLimitFile returns the path of the lock file holding the shared
+// rate limiter state. It lives in the cache directory so that every
+// invocation using the same cache shares one budget.
+func (c *Config) GetRateLimitFile() string {
+	return filepath.Join(c.GetCacheDir(), "ratelimit.lock")
+}
+
 // IsCacheEnabled returns true if caching is enabled
 func (c *Config) IsCacheEnabled() bool {
 	return c.Cache.Enabled && c.Cache.TTL > 0
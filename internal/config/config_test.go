@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadDefaults(t *testing.T) {
@@ -27,6 +28,12 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.Cache.TTL != 604800 {
 		t.Errorf("Expected default TTL 604800, got %d", cfg.Cache.TTL)
 	}
+	if cfg.Cache.Backend != "dir" {
+		t.Errorf("Expected default cache backend 'dir', got %q", cfg.Cache.Backend)
+	}
+	if cfg.Cache.Compression != "none" {
+		t.Errorf("Expected default cache compression 'none', got %q", cfg.Cache.Compression)
+	}
 	if cfg.Output.Format != "table" {
 		t.Errorf("Expected default format 'table', got %q", cfg.Output.Format)
 	}
@@ -334,3 +341,321 @@ func TestLoadNonExistentConfigFile(t *testing.T) {
 		t.Errorf("Load() with no config file should not error, got %v", err)
 	}
 }
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(configPath, []byte("api:\n  timeout: 7\n"), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+	t.Setenv("GROKIPEDIA_CONFIG", configPath)
+
+	cfg, err := Load(GlobalFlags{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.API.Timeout != 7 {
+		t.Errorf("Expected timeout 7 from GROKIPEDIA_CONFIG file, got %d", cfg.API.Timeout)
+	}
+
+	// The flag takes precedence
+	_, err = Load(GlobalFlags{ConfigFile: filepath.Join(t.TempDir(), "missing.yml")})
+	if err == nil {
+		t.Error("Expected an error for the missing --config file")
+	}
+}
+
+func TestLoadRetryConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configContent := `
+api:
+  retry:
+    max_attempts: 5
+    base_delay: 250ms
+    statuses: [502, 503, 504]
+    errors: [reset, timeout]
+`
+	configPath := filepath.Join(tmpDir, "config.yml")
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	cfg, err := Load(GlobalFlags{ConfigFile: configPath})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	retry := cfg.API.Retry
+	if retry.MaxAttempts != 5 {
+		t.Errorf("Expected max attempts 5 from file, got %d", retry.MaxAttempts)
+	}
+	if retry.BaseDelay != 250*time.Millisecond {
+		t.Errorf("Expected base delay 250ms from file, got %v", retry.BaseDelay)
+	}
+	if retry.MaxDelay != 30*time.Second {
+		t.Errorf("Expected default max delay 30s, got %v", retry.MaxDelay)
+	}
+	if len(retry.Statuses) != 3 || retry.Statuses[0] != 502 {
+		t.Errorf("Expected statuses [502 503 504] from file, got %v", retry.Statuses)
+	}
+	if len(retry.Errors) != 2 || retry.Errors[0] != "reset" {
+		t.Errorf("Expected errors [reset timeout] from file, got %v", retry.Errors)
+	}
+
+	// The environment overrides the file
+	t.Setenv("GROKIPEDIA_RETRY_ERRORS", "eof,dns")
+	cfg, err = Load(GlobalFlags{ConfigFile: configPath})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if errs := cfg.API.Retry.Errors; len(errs) != 2 || errs[0] != "eof" || errs[1] != "dns" {
+		t.Errorf("Expected errors [eof dns] from the environment, got %v", errs)
+	}
+
+	// Flags override the file
+	cfg, err = Load(GlobalFlags{
+		ConfigFile:    configPath,
+		MaxAttempts:   1,
+		MaxRetryDelay: 5 * time.Second,
+		RetryStatuses: []int{429},
+		RetryErrors:   []string{"none"},
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	retry = cfg.API.Retry
+	if retry.MaxAttempts != 1 {
+		t.Errorf("Expected max attempts 1 from flag, got %d", retry.MaxAttempts)
+	}
+	if retry.MaxDelay != 5*time.Second {
+		t.Errorf("Expected max delay 5s from flag, got %v", retry.MaxDelay)
+	}
+	if len(retry.Statuses) != 1 || retry.Statuses[0] != 429 {
+		t.Errorf("Expected statuses [429] from flag, got %v", retry.Statuses)
+	}
+	if len(retry.Errors) != 1 || retry.Errors[0] != "none" {
+		t.Errorf("Expected errors [none] from flag, got %v", retry.Errors)
+	}
+}
+
+func TestLoadRateLimitConfig(t *testing.T) {
+	cfg, err := Load(GlobalFlags{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.API.RateLimit.RequestsPerSecond != 0 {
+		t.Errorf("Expected rate limiting disabled by default, got %v", cfg.API.RateLimit.RequestsPerSecond)
+	}
+
+	cfg, err = Load(GlobalFlags{CacheDir: "/custom/cache", RateLimit: 2.5, RateBurst: 4})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.API.RateLimit.RequestsPerSecond != 2.5 {
+		t.Errorf("Expected 2.5 requests/second from flag, got %v", cfg.API.RateLimit.RequestsPerSecond)
+	}
+	if cfg.API.RateLimit.Burst != 4 {
+		t.Errorf("Expected burst 4 from flag, got %d", cfg.API.RateLimit.Burst)
+	}
+	if got := cfg.GetRateLimitFile(); got != filepath.Join("/custom/cache", "ratelimit.lock") {
+		t.Errorf("Expected rate limit file in the cache directory, got %q", got)
+	}
+}
+
+func TestLoadStaleCacheWindows(t *testing.T) {
+	tmpDir := t.TempDir()
+	configContent := `
+cache:
+  stale_if_error: 86400
+  stale_while_revalidate: 300
+`
+	configPath := filepath.Join(tmpDir, "config.yml")
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	cfg, err := Load(GlobalFlags{ConfigFile: configPath})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := cfg.GetStaleIfError(); got != 24*time.Hour {
+		t.Errorf("GetStaleIfError() = %v, want 24h", got)
+	}
+	if got := cfg.GetStaleWhileRevalidate(); got != 5*time.Minute {
+		t.Errorf("GetStaleWhileRevalidate() = %v, want 5m", got)
+	}
+}
+
+func TestLoadCacheLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	configContent := `
+cache:
+  max_size: 104857600
+  max_entries: 5000
+`
+	configPath := filepath.Join(tmpDir, "config.yml")
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	cfg, err := Load(GlobalFlags{ConfigFile: configPath})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Cache.MaxSize != 100*1024*1024 {
+		t.Errorf("Cache.MaxSize = %d, want 104857600", cfg.Cache.MaxSize)
+	}
+	if cfg.Cache.MaxEntries != 5000 {
+		t.Errorf("Cache.MaxEntries = %d, want 5000", cfg.Cache.MaxEntries)
+	}
+
+	t.Setenv("GROKIPEDIA_CACHE_MAX_ENTRIES", "10")
+	cfg, err = Load(GlobalFlags{ConfigFile: configPath})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Cache.MaxEntries != 10 {
+		t.Errorf("Cache.MaxEntries = %d, want 10 from env", cfg.Cache.MaxEntries)
+	}
+}
+
+func TestLoadCacheBackend(t *testing.T) {
+	t.Setenv("GROKIPEDIA_CACHE_BACKEND", "bolt")
+
+	cfg, err := Load(GlobalFlags{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Cache.Backend != "bolt" {
+		t.Errorf("Cache.Backend = %q, want bolt from env", cfg.Cache.Backend)
+	}
+}
+
+func TestLoadOffline(t *testing.T) {
+	cfg, err := Load(GlobalFlags{Offline: true})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Cache.Offline {
+		t.Error("Expected --offline to enable offline mode")
+	}
+
+	t.Setenv("GROKIPEDIA_OFFLINE", "true")
+	cfg, err = Load(GlobalFlags{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Cache.Offline {
+		t.Error("Expected GROKIPEDIA_OFFLINE to enable offline mode")
+	}
+}
+
+func TestLoadNoCacheEnv(t *testing.T) {
+	t.Setenv("GROKIPEDIA_NO_CACHE", "1")
+	cfg, err := Load(GlobalFlags{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Cache.Enabled || cfg.IsCacheEnabled() {
+		t.Error("Expected GROKIPEDIA_NO_CACHE to disable the cache")
+	}
+
+	t.Setenv("GROKIPEDIA_NO_CACHE", "false")
+	cfg, err = Load(GlobalFlags{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Cache.Enabled {
+		t.Error("Expected GROKIPEDIA_NO_CACHE=false to keep the cache enabled")
+	}
+}
+
+func TestLoadDiagnostics(t *testing.T) {
+	cfg, err := Load(GlobalFlags{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Verbose || cfg.Debug {
+		t.Error("Expected verbose and debug output off by default")
+	}
+
+	cfg, err = Load(GlobalFlags{Verbose: true})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Verbose || cfg.Debug {
+		t.Errorf("Expected --verbose to enable only verbose output, got verbose=%v debug=%v", cfg.Verbose, cfg.Debug)
+	}
+
+	t.Setenv("GROKIPEDIA_VERBOSE", "true")
+	t.Setenv("GROKIPEDIA_DEBUG", "1")
+	cfg, err = Load(GlobalFlags{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Verbose || !cfg.Debug {
+		t.Errorf("Expected GROKIPEDIA_VERBOSE and GROKIPEDIA_DEBUG to enable output, got verbose=%v debug=%v", cfg.Verbose, cfg.Debug)
+	}
+}
+
+func TestLoadCacheCompression(t *testing.T) {
+	t.Setenv("GROKIPEDIA_CACHE_COMPRESSION", "zstd")
+
+	cfg, err := Load(GlobalFlags{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Cache.Compression != "zstd" {
+		t.Errorf("Cache.Compression = %q, want zstd from env", cfg.Cache.Compression)
+	}
+}
+
+func TestLoadTTLByEndpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	configContent := `
+cache:
+  ttl: 604800
+  ttl_by_endpoint:
+    typeahead: 300
+    edits: 600
+    edits-by-slug: 600
+`
+	configPath := filepath.Join(tmpDir, "config.yml")
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	cfg, err := Load(GlobalFlags{ConfigFile: configPath})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := map[string]int{"typeahead": 300, "edits": 600, "edits-by-slug": 600}
+	if len(cfg.Cache.TTLByEndpoint) != len(want) {
+		t.Fatalf("Cache.TTLByEndpoint = %v, want %v", cfg.Cache.TTLByEndpoint, want)
+	}
+	for name, ttl := range want {
+		if cfg.Cache.TTLByEndpoint[name] != ttl {
+			t.Errorf("Cache.TTLByEndpoint[%q] = %d, want %d", name, cfg.Cache.TTLByEndpoint[name], ttl)
+		}
+	}
+
+	// --cache-ttl overrides every endpoint
+	cfg, err = Load(GlobalFlags{ConfigFile: configPath, CacheTTL: 60})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Cache.TTL != 60 || len(cfg.Cache.TTLByEndpoint) != 0 {
+		t.Errorf("Expected --cache-ttl to replace per-endpoint TTLs, got ttl=%d by endpoint=%v", cfg.Cache.TTL, cfg.Cache.TTLByEndpoint)
+	}
+
+	// So does GROKIPEDIA_CACHE_TTL
+	t.Setenv("GROKIPEDIA_CACHE_TTL", "120")
+	cfg, err = Load(GlobalFlags{ConfigFile: configPath})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Cache.TTL != 120 || len(cfg.Cache.TTLByEndpoint) != 0 {
+		t.Errorf("Expected GROKIPEDIA_CACHE_TTL to replace per-endpoint TTLs, got ttl=%d by endpoint=%v", cfg.Cache.TTL, cfg.Cache.TTLByEndpoint)
+	}
+}
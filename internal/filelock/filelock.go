@@ -0,0 +1,74 @@
+// Package filelock provides advisory, whole-file locks used to coordinate
+// concurrent grokipedia processes sharing state on disk.
+package filelock
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// File is an open file holding an exclusive advisory lock
+type File struct {
+	*os.File
+}
+
+// Lock opens path, creating it and its parent directory if needed, and
+// blocks until an exclusive lock on it is acquired. Release the lock with
+// Unlock.
+func Lock(path string) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lock(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return &File{File: f}, nil
+}
+
+// Unlock releases the lock and closes the file
+func (f *File) Unlock() error {
+	err := unlock(f.File)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Update locks path and replaces its contents with the result of update,
+// which receives the current contents (empty for a new file). Returning nil
+// data leaves the file unchanged.
+func Update(path string, update func(data []byte) ([]byte, error)) error {
+	f, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Unlock() }()
+
+	current, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	data, err := update(current)
+	if err != nil || data == nil {
+		return err
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package filelock
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockIsExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "test.lock")
+
+	first, err := Lock(path)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	acquired := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		second, err := Lock(path)
+		if err != nil {
+			t.Errorf("second Lock() error = %v", err)
+			return
+		}
+		close(acquired)
+		_ = second.Unlock()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected second Lock to block while the first is held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected second Lock to succeed after Unlock")
+	}
+	wg.Wait()
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter.lock")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(path, func(data []byte) ([]byte, error) {
+				n, _ := strconv.Atoi(string(data))
+				return []byte(strconv.Itoa(n + 1)), nil
+			})
+			if err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// A nil result leaves the file unchanged
+	var got string
+	if err := Update(path, func(data []byte) ([]byte, error) {
+		got = string(data)
+		return nil, nil
+	}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got != "20" {
+		t.Errorf("Expected 20 serialized increments, got %q", got)
+	}
+}
@@ -1,9 +1,18 @@
 package formatter
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/grokipedia/cli/internal/query"
+	"github.com/rodaine/table"
+	"gopkg.in/yaml.v3"
 )
 
 // Formatter is the interface for all output formatters
@@ -25,16 +34,139 @@ func (f *JSONFormatter) Format(data interface{}, w io.Writer) error {
 	return encoder.Encode(data)
 }
 
-// TableFormatter outputs data as an ASCII table
+// NDJSONFormatter outputs data as newline-delimited JSON: one line per item
+// of an Itemized value, or a single line for anything else
+type NDJSONFormatter struct{}
+
+// Format implements the Formatter interface
+func (f *NDJSONFormatter) Format(data interface{}, w io.Writer) error {
+	items, ok := data.(Itemized)
+	if !ok {
+		return json.NewEncoder(w).Encode(data)
+	}
+
+	enc := json.NewEncoder(w)
+	for _, item := range items.Items() {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteNDJSON writes each item of seq to w as a line of JSON as soon as it
+// is yielded, so that long listings are written with constant memory. It
+// stops at the first error, after the items before it have been written.
+func WriteNDJSON[T any](w io.Writer, seq iter.Seq2[T, error]) error {
+	enc := json.NewEncoder(w)
+	for item, err := range seq {
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TableFormatter outputs data as an aligned text table
 type TableFormatter struct {
-	Headers []string
+	// Color makes the headers bold
+	Color bool
+	// Fields, if set, chooses the columns; see Options
+	Fields []string
 }
 
 // Format implements the Formatter interface
 func (f *TableFormatter) Format(data interface{}, w io.Writer) error {
-	// Table formatting is command-specific
-	// Each command will implement its own table formatting
-	return fmt.Errorf("table formatter requires command-specific implementation")
+	t, err := tableOf(data, f.Fields, FormatTable)
+	if err != nil {
+		return err
+	}
+
+	if len(t.Rows) == 0 && t.Empty != "" {
+		_, err := fmt.Fprintln(w, t.Empty)
+		return err
+	}
+
+	headers := make([]interface{}, len(t.Headers))
+	for i, h := range t.Headers {
+		headers[i] = h
+	}
+	tbl := table.New(headers...).WithWriter(w)
+	if f.Color {
+		tbl.WithHeaderFormatter(func(format string, vals ...interface{}) string {
+			return fmt.Sprintf("\033[1m%s\033[0m", fmt.Sprintf(format, vals...))
+		})
+	}
+	for _, row := range t.Rows {
+		cells := make([]interface{}, len(row))
+		for i, c := range row {
+			if t.MaxWidth > 0 {
+				c = truncate(t.MaxWidth, c)
+			}
+			cells[i] = c
+		}
+		tbl.AddRow(cells...)
+	}
+	tbl.Print()
+
+	if t.Footer != "" {
+		_, err := fmt.Fprintf(w, "\n%s\n", t.Footer)
+		return err
+	}
+	return nil
+}
+
+// CSVFormatter outputs the table of a Renderable as delimited text, quoted
+// as in RFC 4180
+type CSVFormatter struct {
+	// Comma is the field delimiter; zero means ','
+	Comma rune
+	// NoHeader leaves out the header row
+	NoHeader bool
+	// Fields, if set, chooses the columns; see Options
+	Fields []string
+}
+
+// Format implements the Formatter interface
+func (f *CSVFormatter) Format(data interface{}, w io.Writer) error {
+	format := FormatCSV
+	if f.Comma == '\t' {
+		format = FormatTSV
+	}
+	t, err := tableOf(data, f.Fields, format)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if f.Comma != 0 {
+		cw.Comma = f.Comma
+	}
+	if !f.NoHeader {
+		if err := cw.Write(t.Headers); err != nil {
+			return err
+		}
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// tableOf returns the table of data, with the given columns if fields is
+// set
+func tableOf(data interface{}, fields []string, format FormatType) (Table, error) {
+	if len(fields) > 0 {
+		return fieldTable(data, fields)
+	}
+	r, err := renderable(data, format)
+	if err != nil {
+		return Table{}, err
+	}
+	return r.Table(), nil
 }
 
 // MarkdownFormatter outputs data as Markdown
@@ -42,8 +174,11 @@ type MarkdownFormatter struct{}
 
 // Format implements the Formatter interface
 func (f *MarkdownFormatter) Format(data interface{}, w io.Writer) error {
-	// Markdown formatting is command-specific
-	return fmt.Errorf("markdown formatter requires command-specific implementation")
+	r, err := renderable(data, FormatMarkdown)
+	if err != nil {
+		return err
+	}
+	return writeBlocks(w, markdownBlocks(r.Document()))
 }
 
 // PlainFormatter outputs data as plain text
@@ -51,18 +186,53 @@ type PlainFormatter struct{}
 
 // Format implements the Formatter interface
 func (f *PlainFormatter) Format(data interface{}, w io.Writer) error {
-	// Plain text formatting is command-specific
-	return fmt.Errorf("plain formatter requires command-specific implementation")
+	r, err := renderable(data, FormatPlain)
+	if err != nil {
+		return err
+	}
+	return writeBlocks(w, plainBlocks(r.Document()))
 }
 
-// YAMLFormatter outputs data as YAML
+// YAMLFormatter outputs data as YAML. Keys are named and ordered as in the
+// JSON output.
 type YAMLFormatter struct{}
 
 // Format implements the Formatter interface
 func (f *YAMLFormatter) Format(data interface{}, w io.Writer) error {
-	// YAML formatting uses the yaml package
-	// This is a placeholder - actual implementation would use gopkg.in/yaml.v3
-	return fmt.Errorf("yaml formatter requires command-specific implementation")
+	// Going through JSON keeps the json tags of the API models
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(encoded, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle clears the flow and quoting styles that decoding JSON leaves
+// on n and its children, so that they encode as block YAML. Strings keep
+// their quotes where encoding them directly would quote them, as with "yes"
+// or "123".
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+		if out, err := yaml.Marshal(n.Value); err == nil && strings.HasPrefix(string(out), `"`) {
+			n.Style = yaml.DoubleQuotedStyle
+		}
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
 }
 
 // ListFormatter outputs data as a newline-delimited list
@@ -70,8 +240,24 @@ type ListFormatter struct{}
 
 // Format implements the Formatter interface
 func (f *ListFormatter) Format(data interface{}, w io.Writer) error {
-	// List formatting is command-specific
-	return fmt.Errorf("list formatter requires command-specific implementation")
+	var lines []string
+	switch d := data.(type) {
+	case []string:
+		lines = d
+	default:
+		r, err := renderable(data, FormatList)
+		if err != nil {
+			return err
+		}
+		lines = r.List()
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // FormatType represents the output format type
@@ -84,15 +270,60 @@ const (
 	FormatPlain    FormatType = "plain"
 	FormatYAML     FormatType = "yaml"
 	FormatList     FormatType = "list"
+	FormatCSV      FormatType = "csv"
+	FormatTSV      FormatType = "tsv"
+	FormatNDJSON   FormatType = "ndjson"
+	FormatTemplate FormatType = "template"
 )
 
+// Formats lists the output formats every command accepts
+var Formats = []string{"table", "json", "ndjson", "yaml", "markdown", "plain", "list", "template"}
+
+// TabularFormats lists the output formats of commands that print rows,
+// which add the delimited formats to Formats
+var TabularFormats = append(slices.Clone(Formats), "csv", "tsv")
+
+// Options configures the formatters returned by NewFormatterWithOptions
+type Options struct {
+	// Color enables terminal colors where the format uses them
+	Color bool
+	// NoHeader leaves out the header row of the delimited formats
+	NoHeader bool
+	// Template is executed by the template format; see ParseTemplate
+	Template *template.Template
+	// Fields chooses the columns of the table and delimited formats, as
+	// dotted paths of JSON names read from each item of a listing, such as
+	// stats.totalViews
+	Fields []string
+	// Query, if set, selects what is written from the data, which is
+	// formatted as a Value
+	Query *query.Query
+}
+
 // NewFormatter creates a formatter for the given format type
 func NewFormatter(format FormatType) Formatter {
+	return NewFormatterWithOptions(format, Options{})
+}
+
+// NewFormatterWithOptions creates a formatter for the given format type.
+// Unknown formats fall back to JSON.
+func NewFormatterWithOptions(format FormatType, opts Options) Formatter {
+	f := newFormatter(format, opts)
+	if opts.Query != nil {
+		return &queryFormatter{query: opts.Query, next: f}
+	}
+	return f
+}
+
+// newFormatter creates the formatter for format, before any query
+func newFormatter(format FormatType, opts Options) Formatter {
 	switch format {
 	case FormatJSON:
 		return &JSONFormatter{Indent: true}
+	case FormatNDJSON:
+		return &NDJSONFormatter{}
 	case FormatTable:
-		return &TableFormatter{}
+		return &TableFormatter{Color: opts.Color, Fields: opts.Fields}
 	case FormatMarkdown:
 		return &MarkdownFormatter{}
 	case FormatPlain:
@@ -101,6 +332,12 @@ func NewFormatter(format FormatType) Formatter {
 		return &YAMLFormatter{}
 	case FormatList:
 		return &ListFormatter{}
+	case FormatCSV:
+		return &CSVFormatter{NoHeader: opts.NoHeader, Fields: opts.Fields}
+	case FormatTSV:
+		return &CSVFormatter{Comma: '\t', NoHeader: opts.NoHeader, Fields: opts.Fields}
+	case FormatTemplate:
+		return &TemplateFormatter{Template: opts.Template}
 	default:
 		return &JSONFormatter{Indent: true}
 	}
@@ -2,8 +2,11 @@ package formatter
 
 import (
 	"bytes"
+	"errors"
+	"strconv"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestJSONFormatter(t *testing.T) {
@@ -134,70 +137,283 @@ func TestValidateFormatErrorMessage(t *testing.T) {
 	}
 }
 
-func TestTableFormatter(t *testing.T) {
-	f := &TableFormatter{
-		Headers: []string{"Col1", "Col2"},
+// fruits is a Renderable used to test the text formatters
+type fruits []string
+
+func (f fruits) Table() Table {
+	t := Table{Headers: []string{"Name", "Length"}, Empty: "No fruit.", Footer: "Total: 2"}
+	for _, name := range f {
+		t.Rows = append(t.Rows, []string{name, strconv.Itoa(len(name))})
+	}
+	return t
+}
+
+func (f fruits) Document() Document {
+	doc := Document{Heading: "Fruit", Empty: "No fruit."}
+	for _, name := range f {
+		doc.Items = append(doc.Items, Item{Title: name, Link: "/" + name, Details: []string{"tasty"}})
+	}
+	return doc
+}
+
+func (f fruits) List() []string {
+	return f
+}
+
+// article is a Renderable document with a title, fields and sections
+type article struct{}
+
+func (article) Table() Table { return Table{} }
+
+func (article) List() []string { return nil }
+
+func (article) Document() Document {
+	return Document{
+		Title:       "Go",
+		Description: "A language",
+		Body:        "Go is simple.",
+		Fields:      []Field{{Name: "Slug", Value: "Go"}, {Name: "Views", Value: "3"}},
+		Sections:    []Section{{Title: "Citations", Items: []Item{{Title: "Site", Link: "https://go.dev"}}}},
 	}
+}
+
+func TestNDJSONFormatter(t *testing.T) {
 	var buf bytes.Buffer
+	if err := (&NDJSONFormatter{}).Format(items{"a", "b"}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if buf.String() != "\"a\"\n\"b\"\n" {
+		t.Errorf("Expected one line per item, got %q", buf.String())
+	}
 
-	data := map[string]string{"key": "value"}
-	err := f.Format(data, &buf)
+	buf.Reset()
+	if err := (&NDJSONFormatter{}).Format(map[string]int{"a": 1, "b": 2}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if buf.String() != "{\"a\":1,\"b\":2}\n" {
+		t.Errorf("Expected the value on one line, got %q", buf.String())
+	}
+}
 
-	// Table formatter returns error as it requires command-specific implementation
-	if err == nil {
-		t.Error("TableFormatter.Format() should return error for generic data")
+// items is an Itemized list of strings
+type items []string
+
+func (l items) Items() []interface{} {
+	values := make([]interface{}, len(l))
+	for i, item := range l {
+		values[i] = item
 	}
+	return values
 }
 
-func TestMarkdownFormatter(t *testing.T) {
-	f := &MarkdownFormatter{}
+func TestWriteNDJSON(t *testing.T) {
+	failure := errors.New("page 2 failed")
+	seq := func(yield func(int, error) bool) {
+		for i := 1; i <= 2; i++ {
+			if !yield(i, nil) {
+				return
+			}
+		}
+		yield(0, failure)
+	}
+
 	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, seq); !errors.Is(err, failure) {
+		t.Errorf("Expected the iterator error, got %v", err)
+	}
+	if buf.String() != "1\n2\n" {
+		t.Errorf("Expected the items before the error, got %q", buf.String())
+	}
+}
 
-	data := map[string]string{"key": "value"}
-	err := f.Format(data, &buf)
+func TestTableFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&TableFormatter{}).Format(fruits{"apple", "fig"}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
 
-	// Markdown formatter returns error as it requires command-specific implementation
-	if err == nil {
-		t.Error("MarkdownFormatter.Format() should return error for generic data")
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[0], "Name") || !strings.Contains(lines[0], "Length") {
+		t.Errorf("Expected headers on the first line, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "apple") || !strings.HasPrefix(lines[2], "fig") {
+		t.Errorf("Expected a row per item, got %q", buf.String())
+	}
+	if !strings.HasSuffix(buf.String(), "\nTotal: 2\n") {
+		t.Errorf("Expected footer after a blank line, got %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "\033[") {
+		t.Error("Expected no colors by default")
+	}
+
+	buf.Reset()
+	if err := (&TableFormatter{Color: true}).Format(fruits{"apple"}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "\033[1m") {
+		t.Errorf("Expected bold headers with Color, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := (&TableFormatter{}).Format(fruits{}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if buf.String() != "No fruit.\n" {
+		t.Errorf("Expected empty message alone, got %q", buf.String())
 	}
 }
 
-func TestPlainFormatter(t *testing.T) {
-	f := &PlainFormatter{}
+func TestCSVFormatter(t *testing.T) {
+	data := fruits{`say "hi"`, "a,b"}
+
 	var buf bytes.Buffer
+	if err := (&CSVFormatter{}).Format(data, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	want := "Name,Length\n\"say \"\"hi\"\"\",8\n\"a,b\",3\n"
+	if buf.String() != want {
+		t.Errorf("Format() = %q, want %q", buf.String(), want)
+	}
 
-	data := map[string]string{"key": "value"}
-	err := f.Format(data, &buf)
+	buf.Reset()
+	if err := NewFormatterWithOptions(FormatTSV, Options{NoHeader: true}).Format(data, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	want = "\"say \"\"hi\"\"\"\t8\na,b\t3\n"
+	if buf.String() != want {
+		t.Errorf("Format() = %q, want %q", buf.String(), want)
+	}
 
-	// Plain formatter returns error as it requires command-specific implementation
-	if err == nil {
-		t.Error("PlainFormatter.Format() should return error for generic data")
+	buf.Reset()
+	if err := (&CSVFormatter{}).Format(fruits{}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if buf.String() != "Name,Length\n" {
+		t.Errorf("Expected only the header without rows, got %q", buf.String())
 	}
 }
 
-func TestYAMLFormatter(t *testing.T) {
-	f := &YAMLFormatter{}
+func TestTableFormatterMaxWidth(t *testing.T) {
 	var buf bytes.Buffer
+	if err := (&TableFormatter{}).Format(wide{}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "abcdefg...") || strings.Contains(buf.String(), "abcdefghijk") {
+		t.Errorf("Expected cell truncated to 10 characters, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "αβγδεζη...") || !utf8.ValidString(buf.String()) {
+		t.Errorf("Expected multi-byte cell truncated to 10 characters, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := (&CSVFormatter{}).Format(wide{}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "abcdefghijklmnop") {
+		t.Errorf("Expected full cell in csv, got %q", buf.String())
+	}
+}
+
+// wide is a Renderable whose table truncates its long cells
+type wide struct{}
+
+func (wide) Table() Table {
+	return Table{Headers: []string{"Value"}, Rows: [][]string{{"abcdefghijklmnop"}, {"αβγδεζηθικλμνξ"}}, MaxWidth: 10}
+}
 
+func (wide) Document() Document { return Document{} }
+
+func (wide) List() []string { return nil }
+
+func TestTextFormattersRejectOtherData(t *testing.T) {
 	data := map[string]string{"key": "value"}
-	err := f.Format(data, &buf)
+	for _, format := range []FormatType{FormatTable, FormatMarkdown, FormatPlain, FormatList, FormatCSV} {
+		var buf bytes.Buffer
+		if err := NewFormatter(format).Format(data, &buf); err == nil {
+			t.Errorf("%s: expected error for data that is not Renderable", format)
+		}
+	}
+}
 
-	// YAML formatter returns error as it requires command-specific implementation
-	if err == nil {
-		t.Error("YAMLFormatter.Format() should return error for generic data")
+func TestMarkdownFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&MarkdownFormatter{}).Format(article{}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	want := "# Go\n\nA language\n\nGo is simple.\n\n**Slug:** Go\n**Views:** 3\n\n## Citations\n- [Site](https://go.dev)\n"
+	if buf.String() != want {
+		t.Errorf("Format() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := (&MarkdownFormatter{}).Format(fruits{"apple", "fig"}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	want = "# Fruit\n\n- [apple](/apple)\n  tasty\n\n- [fig](/fig)\n  tasty\n"
+	if buf.String() != want {
+		t.Errorf("Format() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := (&MarkdownFormatter{}).Format(fruits{}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if buf.String() != "# Fruit\n\nNo fruit.\n" {
+		t.Errorf("Expected empty message, got %q", buf.String())
 	}
 }
 
-func TestListFormatter(t *testing.T) {
-	f := &ListFormatter{}
+func TestPlainFormatter(t *testing.T) {
 	var buf bytes.Buffer
+	if err := (&PlainFormatter{}).Format(article{}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
 
-	data := []string{"item1", "item2", "item3"}
-	err := f.Format(data, &buf)
+	want := "Title: Go\nDescription: A language\n\nContent:\nGo is simple.\n\nSlug: Go\nViews: 3\n\nCitations:\n- Site (https://go.dev)\n"
+	if buf.String() != want {
+		t.Errorf("Format() = %q, want %q", buf.String(), want)
+	}
+}
 
-	// List formatter returns error as it requires command-specific implementation
-	if err == nil {
-		t.Error("ListFormatter.Format() should return error for generic data")
+func TestYAMLFormatter(t *testing.T) {
+	data := struct {
+		TotalCount int      `json:"totalCount"`
+		Name       string   `json:"name"`
+		Code       string   `json:"code"`
+		Tags       []string `json:"tags"`
+	}{TotalCount: 2, Name: "Go", Code: "123", Tags: []string{"a", "b"}}
+
+	var buf bytes.Buffer
+	if err := (&YAMLFormatter{}).Format(data, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	want := "totalCount: 2\nname: Go\ncode: \"123\"\ntags:\n  - a\n  - b\n"
+	if buf.String() != want {
+		t.Errorf("Format() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestListFormatter(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+	}{
+		{"strings", []string{"item1", "item2"}},
+		{"renderable", fruits{"item1", "item2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (&ListFormatter{}).Format(tt.data, &buf); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if buf.String() != "item1\nitem2\n" {
+				t.Errorf("Format() = %q, want one item per line", buf.String())
+			}
+		})
 	}
 }
 
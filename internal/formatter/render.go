@@ -0,0 +1,205 @@
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Renderable is implemented by the types of package render, which describe
+// the API responses, so that they can be written in every text format. Each
+// method describes the value once, and the formatters decide how it looks.
+type Renderable interface {
+	// Table returns the value as rows, for the table format
+	Table() Table
+	// Document returns the value as a document, for the markdown and plain
+	// formats
+	Document() Document
+	// List returns one line per item, for the list format
+	List() []string
+}
+
+// Itemized is implemented by responses that list several items, which the
+// ndjson format writes one per line
+type Itemized interface {
+	Items() []interface{}
+}
+
+// Table is tabular data
+type Table struct {
+	Headers []string
+	Rows    [][]string
+	// Empty is written instead of the table when there are no rows
+	Empty string
+	// Footer is written after the table, separated by a blank line
+	Footer string
+	// MaxWidth, if set, truncates cells longer than MaxWidth characters in
+	// the table format. The delimited formats always write cells in full.
+	MaxWidth int
+}
+
+// Document is a titled text with a list of items, labelled fields and
+// sections of links
+type Document struct {
+	// Title is the title of the value described, such as a page title
+	Title string
+	// Heading heads documents listing several values, such as search results
+	Heading     string
+	Description string
+	Body        string
+	Items       []Item
+	Fields      []Field
+	Sections    []Section
+	// Empty is written instead of Items and Fields when there are none
+	Empty string
+}
+
+// Item is an entry in a document's list
+type Item struct {
+	Title string
+	// Link, if set, is what the title links to
+	Link    string
+	Details []string
+}
+
+// Field is a labelled value in a document
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Section is a titled list of items that ends a document
+type Section struct {
+	Title string
+	Items []Item
+}
+
+// renderable returns data as a Renderable, or an error naming the format
+// that cannot write it
+func renderable(data interface{}, format FormatType) (Renderable, error) {
+	r, ok := data.(Renderable)
+	if !ok {
+		return nil, fmt.Errorf("%s output is not supported for %T", format, data)
+	}
+	return r, nil
+}
+
+// writeBlocks writes the non-empty blocks to w, separated by blank lines
+func writeBlocks(w io.Writer, blocks []string) error {
+	var nonEmpty []string
+	for _, b := range blocks {
+		if b != "" {
+			nonEmpty = append(nonEmpty, b)
+		}
+	}
+	_, err := io.WriteString(w, strings.Join(nonEmpty, "\n"))
+	return err
+}
+
+// markdownBlocks returns the blocks of doc in markdown
+func markdownBlocks(doc Document) []string {
+	var blocks []string
+	if doc.Title != "" {
+		blocks = append(blocks, "# "+doc.Title+"\n")
+	}
+	if doc.Heading != "" {
+		blocks = append(blocks, "# "+doc.Heading+"\n")
+	}
+	if doc.Description != "" {
+		blocks = append(blocks, doc.Description+"\n")
+	}
+	if doc.Body != "" {
+		blocks = append(blocks, doc.Body+"\n")
+	}
+
+	if len(doc.Items) == 0 && len(doc.Fields) == 0 && doc.Empty != "" {
+		blocks = append(blocks, doc.Empty+"\n")
+	}
+	for _, item := range doc.Items {
+		var b strings.Builder
+		b.WriteString(markdownItem(item))
+		for _, d := range item.Details {
+			b.WriteString("  " + d + "\n")
+		}
+		blocks = append(blocks, b.String())
+	}
+
+	var fields strings.Builder
+	for _, f := range doc.Fields {
+		fmt.Fprintf(&fields, "**%s:** %s\n", f.Name, f.Value)
+	}
+	blocks = append(blocks, fields.String())
+
+	for _, s := range doc.Sections {
+		var b strings.Builder
+		b.WriteString("## " + s.Title + "\n")
+		for _, item := range s.Items {
+			b.WriteString(markdownItem(item))
+		}
+		blocks = append(blocks, b.String())
+	}
+	return blocks
+}
+
+// markdownItem returns the list line of item in markdown
+func markdownItem(item Item) string {
+	if item.Link == "" {
+		return "- " + item.Title + "\n"
+	}
+	return fmt.Sprintf("- [%s](%s)\n", item.Title, item.Link)
+}
+
+// plainBlocks returns the blocks of doc in plain text
+func plainBlocks(doc Document) []string {
+	var head strings.Builder
+	if doc.Title != "" {
+		head.WriteString("Title: " + doc.Title + "\n")
+	}
+	if doc.Heading != "" {
+		head.WriteString(doc.Heading + "\n")
+	}
+	if doc.Description != "" {
+		head.WriteString("Description: " + doc.Description + "\n")
+	}
+
+	blocks := []string{head.String()}
+	if doc.Body != "" {
+		blocks = append(blocks, "Content:\n"+doc.Body+"\n")
+	}
+
+	if len(doc.Items) == 0 && len(doc.Fields) == 0 && doc.Empty != "" {
+		blocks = append(blocks, doc.Empty+"\n")
+	}
+	for _, item := range doc.Items {
+		var b strings.Builder
+		b.WriteString(plainItem(item))
+		for _, d := range item.Details {
+			b.WriteString("  " + d + "\n")
+		}
+		blocks = append(blocks, b.String())
+	}
+
+	var fields strings.Builder
+	for _, f := range doc.Fields {
+		fmt.Fprintf(&fields, "%s: %s\n", f.Name, f.Value)
+	}
+	blocks = append(blocks, fields.String())
+
+	for _, s := range doc.Sections {
+		var b strings.Builder
+		b.WriteString(s.Title + ":\n")
+		for _, item := range s.Items {
+			b.WriteString("- " + plainItem(item))
+		}
+		blocks = append(blocks, b.String())
+	}
+	return blocks
+}
+
+// plainItem returns the line of item in plain text
+func plainItem(item Item) string {
+	if item.Link == "" {
+		return item.Title + "\n"
+	}
+	return fmt.Sprintf("%s (%s)\n", item.Title, item.Link)
+}
@@ -0,0 +1,103 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+	"time"
+)
+
+// DateLayout is how timestamps are shown in tables and by the date template
+// function
+const DateLayout = "2006-01-02 15:04"
+
+// TemplateFuncs are the functions available to output templates, besides
+// those passed to ParseTemplate:
+//
+//	date TS [LAYOUT]  formats Unix seconds, in DateLayout by default
+//	truncate N S      shortens S to N characters, ending in "..."
+//	json V            encodes V as compact JSON
+var TemplateFuncs = template.FuncMap{
+	"date":     templateDate,
+	"truncate": truncate,
+	"json":     templateJSON,
+}
+
+// ParseTemplate parses text as an output template, with TemplateFuncs and
+// funcs available to it
+func ParseTemplate(text string, funcs template.FuncMap) (*template.Template, error) {
+	return template.New("output").Funcs(TemplateFuncs).Funcs(funcs).Parse(text)
+}
+
+// TemplateFormatter outputs data through a text/template, executed against
+// the data as given, or the data of a Value
+type TemplateFormatter struct {
+	Template *template.Template
+}
+
+// Format implements the Formatter interface
+func (f *TemplateFormatter) Format(data interface{}, w io.Writer) error {
+	if f.Template == nil {
+		return fmt.Errorf("template output needs a template")
+	}
+	if v, ok := data.(Value); ok {
+		data = v.Data
+	}
+	return f.Template.Execute(w, data)
+}
+
+// templateDate formats a Unix timestamp in seconds. Numbers decoded from
+// JSON, as in the constants or the result of a query, are accepted as well
+// as integers.
+func templateDate(ts interface{}, layout ...string) (string, error) {
+	var seconds int64
+	switch v := ts.(type) {
+	case int64:
+		seconds = v
+	case int:
+		seconds = int64(v)
+	case float64:
+		seconds = int64(v)
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			f, err := v.Float64()
+			if err != nil {
+				return "", fmt.Errorf("date: expected a timestamp, got %q", v)
+			}
+			n = int64(f)
+		}
+		seconds = n
+	default:
+		return "", fmt.Errorf("date: expected a timestamp, got %T", ts)
+	}
+
+	format := DateLayout
+	if len(layout) > 0 {
+		format = layout[0]
+	}
+	return time.Unix(seconds, 0).Format(format), nil
+}
+
+// truncate shortens s to at most n characters, replacing the end with
+// "..." when it is cut
+func truncate(n int, s string) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-3]) + "..."
+}
+
+// templateJSON encodes v as compact JSON
+func templateJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
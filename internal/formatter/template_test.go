@@ -0,0 +1,80 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{range .}}{{.Name | truncate 6}} {{json .Tags}}{{"\n"}}{{end}}`, nil)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+
+	data := []struct {
+		Name string
+		Tags []string
+	}{
+		{Name: "Go", Tags: []string{"lang"}},
+		{Name: "JavaScript", Tags: nil},
+	}
+
+	var buf bytes.Buffer
+	if err := (&TemplateFormatter{Template: tmpl}).Format(data, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if want := "Go [\"lang\"]\nJav... null\n"; buf.String() != want {
+		t.Errorf("Format() = %q, want %q", buf.String(), want)
+	}
+
+	if err := (&TemplateFormatter{}).Format(data, &buf); err == nil {
+		t.Error("Expected error without a template")
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.Local).Unix()
+	tests := []struct {
+		text string
+		data interface{}
+		want string
+	}{
+		{`{{date .}}`, ts, "2024-03-01 12:30"},
+		{`{{date . "Jan 2"}}`, ts, "Mar 1"},
+		{`{{date .}}`, float64(ts), "2024-03-01 12:30"},
+		{`{{date .}}`, json.Number(strconv.FormatInt(ts, 10)), "2024-03-01 12:30"},
+		{`{{date .}}`, json.Number(strconv.FormatInt(ts, 10) + ".5"), "2024-03-01 12:30"},
+		{`{{truncate 3 .}}`, "abcdef", "abc"},
+		{`{{truncate 10 .}}`, "short", "short"},
+		{`{{truncate 4 .}}`, "héllo", "h..."},
+		{`{{json .}}`, map[string]int{"a": 1}, `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := ParseTemplate(tt.text, nil)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%q) error = %v", tt.text, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, tt.data); err != nil {
+			t.Errorf("%s: error = %v", tt.text, err)
+			continue
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s = %q, want %q", tt.text, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestTemplateDateRejectsText(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{date .}}`, nil)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, "yesterday"); err == nil {
+		t.Error("Expected error for a non-numeric timestamp")
+	}
+}
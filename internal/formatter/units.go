@@ -0,0 +1,42 @@
+package formatter
+
+import (
+	"fmt"
+	"time"
+)
+
+// FormatBytes renders a byte count using binary units
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// FormatAge renders a duration truncated to its largest sensible unit
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// FormatExpiry renders when something expires relative to now
+func FormatExpiry(expiresAt, now time.Time) string {
+	if now.After(expiresAt) {
+		return "expired " + FormatAge(now.Sub(expiresAt)) + " ago"
+	}
+	return "in " + FormatAge(expiresAt.Sub(now))
+}
@@ -0,0 +1,55 @@
+package formatter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "30s"},
+		{90 * time.Second, "1m"},
+		{5 * time.Hour, "5h"},
+		{47 * time.Hour, "47h"},
+		{72 * time.Hour, "3d"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatAge(tt.d); got != tt.want {
+			t.Errorf("FormatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatExpiry(t *testing.T) {
+	now := time.Now()
+
+	if got := FormatExpiry(now.Add(time.Hour), now); got != "in 1h" {
+		t.Errorf("FormatExpiry(future) = %q, want %q", got, "in 1h")
+	}
+	if got := FormatExpiry(now.Add(-2*time.Minute), now); got != "expired 2m ago" {
+		t.Errorf("FormatExpiry(past) = %q, want %q", got, "expired 2m ago")
+	}
+}
@@ -0,0 +1,220 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grokipedia/cli/internal/query"
+)
+
+// Value is data decoded from JSON, such as the result of a query. It is
+// written by every format: an array has a record per element, anything else
+// is a single record.
+type Value struct {
+	Data interface{}
+}
+
+var (
+	_ Renderable = Value{}
+	_ Itemized   = Value{}
+)
+
+// Recorder is implemented by values whose --fields records are not the
+// value itself, such as a page response whose fields are those of the page
+type Recorder interface {
+	Record() interface{}
+}
+
+// MarshalJSON encodes the data of v
+func (v Value) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Data)
+}
+
+// Items implements Itemized
+func (v Value) Items() []interface{} {
+	switch d := v.Data.(type) {
+	case []interface{}:
+		return d
+	case nil:
+		return nil
+	}
+	return []interface{}{v.Data}
+}
+
+// Table implements Renderable. Objects have a column per key, in order, and
+// anything else a single Value column.
+func (v Value) Table() Table {
+	records := v.Items()
+	t := Table{Empty: "No results."}
+
+	var columns []string
+	seen := make(map[string]bool)
+	for _, r := range records {
+		if obj, ok := r.(map[string]interface{}); ok {
+			for k := range obj {
+				if !seen[k] {
+					seen[k] = true
+					columns = append(columns, k)
+				}
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	if len(columns) == 0 {
+		t.Headers = []string{"Value"}
+		for _, r := range records {
+			t.Rows = append(t.Rows, []string{cell(r)})
+		}
+		return t
+	}
+
+	t.Headers = columns
+	for _, r := range records {
+		obj, _ := r.(map[string]interface{})
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = cell(obj[c])
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// Document implements Renderable, with an item per record
+func (v Value) Document() Document {
+	doc := Document{Empty: "No results."}
+	for _, r := range v.Items() {
+		doc.Items = append(doc.Items, Item{Title: cell(r)})
+	}
+	return doc
+}
+
+// List implements Renderable, with a line per record
+func (v Value) List() []string {
+	var lines []string
+	for _, r := range v.Items() {
+		lines = append(lines, cell(r))
+	}
+	return lines
+}
+
+// Decode returns data as it would be decoded from its JSON encoding, with
+// numbers as json.Number so that they print as given
+func Decode(data interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// queryFormatter runs a query on the data before writing its result with
+// the wrapped formatter
+type queryFormatter struct {
+	query *query.Query
+	next  Formatter
+}
+
+// Format implements the Formatter interface
+func (f *queryFormatter) Format(data interface{}, w io.Writer) error {
+	v, err := Decode(data)
+	if err != nil {
+		return err
+	}
+	results, err := f.query.Run(v)
+	if err != nil {
+		return err
+	}
+
+	// A query with one result gives that result, as jq would print it
+	if len(results) == 1 {
+		return f.next.Format(Value{Data: results[0]}, w)
+	}
+	if results == nil {
+		results = []interface{}{}
+	}
+	return f.next.Format(Value{Data: results}, w)
+}
+
+// fieldTable returns a table with a column per field, read from each record
+// of data. Fields are dotted paths of JSON names, such as stats.totalViews.
+func fieldTable(data interface{}, fields []string) (Table, error) {
+	var records []interface{}
+	switch d := data.(type) {
+	case Itemized:
+		records = d.Items()
+	case Recorder:
+		records = []interface{}{d.Record()}
+	default:
+		records = []interface{}{data}
+	}
+
+	t := Table{Headers: fields}
+	if r, ok := data.(Renderable); ok {
+		t.Empty = r.Table().Empty
+	}
+	for _, record := range records {
+		v, err := Decode(record)
+		if err != nil {
+			return Table{}, err
+		}
+		row := make([]string, len(fields))
+		for i, field := range fields {
+			row[i] = cell(lookup(v, field))
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// lookup returns the value at a dotted path in v, or nil. Numeric parts
+// index arrays.
+func lookup(v interface{}, path string) interface{} {
+	for _, part := range strings.Split(path, ".") {
+		switch d := v.(type) {
+		case map[string]interface{}:
+			v = d[part]
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(d) {
+				return nil
+			}
+			v = d[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// cell formats a decoded value as a table cell: scalars as text and
+// anything else as JSON
+func cell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,126 @@
+package formatter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/grokipedia/cli/internal/query"
+)
+
+// listing is an Itemized response whose items have nested fields
+type listing struct {
+	Entries []entry `json:"entries"`
+}
+
+type entry struct {
+	Title string     `json:"title"`
+	Stats entryStats `json:"stats"`
+}
+
+type entryStats struct {
+	Views int `json:"totalViews"`
+}
+
+func (l listing) Items() []interface{} {
+	items := make([]interface{}, len(l.Entries))
+	for i, e := range l.Entries {
+		items[i] = e
+	}
+	return items
+}
+
+// wrapped is a Recorder whose fields are those of its entry
+type wrapped struct {
+	Entry entry `json:"entry"`
+}
+
+func (w wrapped) Record() interface{} {
+	return w.Entry
+}
+
+func TestFieldsSelectColumns(t *testing.T) {
+	data := listing{Entries: []entry{{Title: "Go, again", Stats: entryStats{Views: 7}}, {Title: "Rust"}}}
+
+	var buf bytes.Buffer
+	f := NewFormatterWithOptions(FormatCSV, Options{Fields: []string{"stats.totalViews", "title", "missing"}})
+	if err := f.Format(data, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	want := "stats.totalViews,title,missing\n7,\"Go, again\",\n0,Rust,\n"
+	if buf.String() != want {
+		t.Errorf("Format() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	f = NewFormatterWithOptions(FormatCSV, Options{Fields: []string{"title", "stats.totalViews"}})
+	if err := f.Format(wrapped{Entry: entry{Title: "Go", Stats: entryStats{Views: 3}}}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if want := "title,stats.totalViews\nGo,3\n"; buf.String() != want {
+		t.Errorf("Format() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestQueryFormatter(t *testing.T) {
+	data := listing{Entries: []entry{{Title: "Go", Stats: entryStats{Views: 700}}, {Title: "Rust", Stats: entryStats{Views: 9}}}}
+
+	tests := []struct {
+		name   string
+		query  string
+		format FormatType
+		want   string
+	}{
+		{"single result as is", ".entries[0].title", FormatJSON, "\"Go\"\n"},
+		{"several results as an array", ".entries[].title", FormatJSON, "[\n  \"Go\",\n  \"Rust\"\n]\n"},
+		{"no results", ".entries[] | select(.title == \"C\")", FormatJSON, "[]\n"},
+		{"list", ".entries[].title", FormatList, "Go\nRust\n"},
+		{"ndjson", ".entries[] | select(.stats.totalViews > 100)", FormatNDJSON, "{\"stats\":{\"totalViews\":700},\"title\":\"Go\"}\n"},
+		{"tsv columns", ".entries", FormatTSV, "stats\ttitle\n\"{\"\"totalViews\"\":700}\"\tGo\n\"{\"\"totalViews\"\":9}\"\tRust\n"},
+		{"tsv scalars", ".entries[].stats.totalViews", FormatTSV, "Value\n700\n9\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := NewFormatterWithOptions(tt.format, Options{Query: q}).Format(data, &buf); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Format() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryWithFieldsAndTemplate(t *testing.T) {
+	data := listing{Entries: []entry{{Title: "Go", Stats: entryStats{Views: 700}}, {Title: "Rust", Stats: entryStats{Views: 9}}}}
+	q, err := query.Parse(".entries[] | select(.stats.totalViews < 100)")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	opts := Options{Query: q, Fields: []string{"title"}, NoHeader: true}
+	if err := NewFormatterWithOptions(FormatCSV, opts).Format(data, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if buf.String() != "Rust\n" {
+		t.Errorf("Expected fields of the query result, got %q", buf.String())
+	}
+
+	tmpl, err := ParseTemplate("{{.title}} has {{.stats.totalViews}} views\n", nil)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	buf.Reset()
+	if err := NewFormatterWithOptions(FormatTemplate, Options{Query: q, Template: tmpl}).Format(data, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if buf.String() != "Rust has 9 views\n" {
+		t.Errorf("Expected template over the query result, got %q", buf.String())
+	}
+}
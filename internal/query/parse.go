@@ -0,0 +1,363 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tokenKind is the kind of a lexical token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+// token is a lexical token of a query
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of query"
+	}
+	return strconv.Quote(t.text)
+}
+
+// lexer splits a query into tokens
+type lexer struct {
+	src string
+	pos int
+}
+
+// next returns the next token
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && strings.ContainsRune(" \t\r\n", rune(l.src[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case strings.ContainsRune(".[]|()", rune(c)):
+		l.pos++
+		return token{kind: tokPunct, text: string(c), pos: start}, nil
+
+	case strings.ContainsRune("=!<>", rune(c)):
+		l.pos++
+		if l.pos < len(l.src) && l.src[l.pos] == '=' {
+			l.pos++
+		}
+		op := l.src[start:l.pos]
+		if op == "=" || op == "!" {
+			return token{}, fmt.Errorf("unexpected %q at %d", op, start)
+		}
+		return token{kind: tokOp, text: op, pos: start}, nil
+
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != '"' {
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{}, fmt.Errorf("unterminated string at %d", start)
+		}
+		l.pos++
+		s, err := strconv.Unquote(l.src[start:l.pos])
+		if err != nil {
+			return token{}, fmt.Errorf("invalid string at %d", start)
+		}
+		return token{kind: tokString, text: s, pos: start}, nil
+
+	case c == '-' || isDigit(c):
+		l.pos++
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.src[start:l.pos], pos: start}, nil
+
+	case isIdentStart(c):
+		for l.pos < len(l.src) && (isIdentStart(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}, nil
+	}
+	return token{}, fmt.Errorf("unexpected %q at %d", c, start)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// parser builds filters from tokens, one token ahead
+type parser struct {
+	lex lexer
+	tok token
+}
+
+func (p *parser) next() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at %d", fmt.Sprintf(format, args...), p.tok.pos)
+}
+
+// is reports whether the current token is the given punctuation or keyword
+func (p *parser) is(kind tokenKind, text string) bool {
+	return p.tok.kind == kind && p.tok.text == text
+}
+
+// expect consumes the given punctuation
+func (p *parser) expect(text string) error {
+	if !p.is(tokPunct, text) {
+		return p.errorf("expected %q, found %s", text, p.tok)
+	}
+	return p.next()
+}
+
+// pipeline parses: term ('|' term)*
+func (p *parser) pipeline() (filter, error) {
+	f, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokPunct, "|") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		g, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		f = pipe(f, g)
+	}
+	return f, nil
+}
+
+// term parses a path or a function
+func (p *parser) term() (filter, error) {
+	if p.tok.kind == tokIdent {
+		name := p.tok.text
+		switch name {
+		case "length", "keys":
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if name == "length" {
+				return length, nil
+			}
+			return keys, nil
+
+		case "select":
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			c, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return selectFilter(c), nil
+		}
+		return nil, p.errorf("unknown function %s", p.tok)
+	}
+	return p.path()
+}
+
+// path parses a path starting with '.'
+func (p *parser) path() (filter, error) {
+	if err := p.expect("."); err != nil {
+		return nil, err
+	}
+
+	f := filter(identity)
+	first := true
+	for {
+		var step filter
+		var err error
+		switch {
+		case (p.tok.kind == tokIdent || p.tok.kind == tokString) && first:
+			step = field(p.tok.text)
+			err = p.next()
+		case p.is(tokPunct, "["):
+			step, err = p.bracket()
+		case p.is(tokPunct, ".") && !first:
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if p.tok.kind != tokIdent && p.tok.kind != tokString && !p.is(tokPunct, "[") {
+				return nil, p.errorf("expected a field name, found %s", p.tok)
+			}
+			first = true
+			continue
+		default:
+			return f, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		f = pipe(f, step)
+		first = false
+	}
+}
+
+// bracket parses '[' ']', '[' number ']' or '[' string ']'
+func (p *parser) bracket() (filter, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	var f filter
+	switch p.tok.kind {
+	case tokNumber:
+		i, err := strconv.Atoi(p.tok.text)
+		if err != nil {
+			return nil, p.errorf("invalid index %s", p.tok)
+		}
+		f = index(i)
+	case tokString:
+		f = field(p.tok.text)
+	default:
+		if !p.is(tokPunct, "]") {
+			return nil, p.errorf("unexpected %s", p.tok)
+		}
+		return iterate, p.next()
+	}
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	return f, p.expect("]")
+}
+
+// or parses: and ('or' and)*
+func (p *parser) or() (condition, error) {
+	c, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokIdent, "or") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		d, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left := c
+		c = func(v interface{}) (bool, error) {
+			ok, err := left(v)
+			if err != nil || ok {
+				return ok, err
+			}
+			return d(v)
+		}
+	}
+	return c, nil
+}
+
+// and parses: comparison ('and' comparison)*
+func (p *parser) and() (condition, error) {
+	c, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokIdent, "and") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		d, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		left := c
+		c = func(v interface{}) (bool, error) {
+			ok, err := left(v)
+			if err != nil || !ok {
+				return ok, err
+			}
+			return d(v)
+		}
+	}
+	return c, nil
+}
+
+// comparison parses: operand (op operand)?
+func (p *parser) comparison() (condition, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokOp {
+		return truthy(left), nil
+	}
+
+	op := p.tok.text
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return compare(left, op, right), nil
+}
+
+// operand parses a path, function or literal
+func (p *parser) operand() (filter, error) {
+	var lit interface{}
+	switch {
+	case p.tok.kind == tokString:
+		lit = p.tok.text
+	case p.tok.kind == tokNumber:
+		if _, err := strconv.ParseFloat(p.tok.text, 64); err != nil {
+			return nil, p.errorf("invalid number %s", p.tok)
+		}
+		lit = json.Number(p.tok.text)
+	case p.is(tokIdent, "true"):
+		lit = true
+	case p.is(tokIdent, "false"):
+		lit = false
+	case p.is(tokIdent, "null"):
+		lit = nil
+	default:
+		return p.term()
+	}
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	return func(interface{}) ([]interface{}, error) {
+		return []interface{}{lit}, nil
+	}, nil
+}
@@ -0,0 +1,322 @@
+// Package query implements the subset of jq used by --query to pick out
+// parts of a response before it is formatted. Queries run on values decoded
+// from JSON: maps, slices, strings, json.Number, booleans and nil.
+//
+// The supported filters are:
+//
+//	.            the input
+//	.foo .["f"]  an object field; missing fields are null
+//	.[2] .[-1]   an array element, counting from the end when negative
+//	.[]          every element of an array or value of an object
+//	a | b        b applied to each output of a
+//	select(c)    the input when c holds, where c compares paths and
+//	             literals with == != < <= > >=, joined by and / or
+//	length       the length of a string, array or object
+//	keys         the sorted keys of an object
+//
+// Paths chain, as in .results[0].slug.
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+)
+
+// Query is a parsed query
+type Query struct {
+	expr string
+	f    filter
+}
+
+// filter maps an input to its outputs
+type filter func(v interface{}) ([]interface{}, error)
+
+// Parse parses a query
+func Parse(expr string) (*Query, error) {
+	p := &parser{lex: lexer{src: expr}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	f, err := p.pipeline()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return &Query{expr: expr, f: f}, nil
+}
+
+// String returns the query as written
+func (q *Query) String() string {
+	return q.expr
+}
+
+// Run returns the outputs of the query for v
+func (q *Query) Run(v interface{}) ([]interface{}, error) {
+	return q.f(v)
+}
+
+// identity is the filter "."
+func identity(v interface{}) ([]interface{}, error) {
+	return []interface{}{v}, nil
+}
+
+// pipe returns a filter applying b to every output of a
+func pipe(a, b filter) filter {
+	return func(v interface{}) ([]interface{}, error) {
+		outs, err := a(v)
+		if err != nil {
+			return nil, err
+		}
+		var results []interface{}
+		for _, out := range outs {
+			res, err := b(out)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, res...)
+		}
+		return results, nil
+	}
+}
+
+// field returns a filter selecting an object field
+func field(name string) filter {
+	return func(v interface{}) ([]interface{}, error) {
+		switch v := v.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case map[string]interface{}:
+			return []interface{}{v[name]}, nil
+		default:
+			return nil, fmt.Errorf("cannot index %s with %q", typeName(v), name)
+		}
+	}
+}
+
+// index returns a filter selecting an array element
+func index(i int) filter {
+	return func(v interface{}) ([]interface{}, error) {
+		switch v := v.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case []interface{}:
+			if i < 0 {
+				i += len(v)
+			}
+			if i < 0 || i >= len(v) {
+				return []interface{}{nil}, nil
+			}
+			return []interface{}{v[i]}, nil
+		default:
+			return nil, fmt.Errorf("cannot index %s with a number", typeName(v))
+		}
+	}
+}
+
+// iterate is the filter ".[]"
+func iterate(v interface{}) ([]interface{}, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		return v, nil
+	case map[string]interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, k := range sortedKeys(v) {
+			values = append(values, v[k])
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("cannot iterate over %s", typeName(v))
+	}
+}
+
+// length is the filter "length"
+func length(v interface{}) ([]interface{}, error) {
+	var n int
+	switch v := v.(type) {
+	case nil:
+	case string:
+		n = utf8.RuneCountInString(v)
+	case []interface{}:
+		n = len(v)
+	case map[string]interface{}:
+		n = len(v)
+	case json.Number, float64:
+		f, _ := number(v)
+		return []interface{}{json.Number(fmt.Sprint(math.Abs(f)))}, nil
+	default:
+		return nil, fmt.Errorf("%s has no length", typeName(v))
+	}
+	return []interface{}{json.Number(fmt.Sprint(n))}, nil
+}
+
+// keys is the filter "keys"
+func keys(v interface{}) ([]interface{}, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s has no keys", typeName(v))
+	}
+	ks := sortedKeys(m)
+	out := make([]interface{}, len(ks))
+	for i, k := range ks {
+		out[i] = k
+	}
+	return []interface{}{out}, nil
+}
+
+// condition reports whether a select condition holds for an input
+type condition func(v interface{}) (bool, error)
+
+// selectFilter returns the filter "select(c)"
+func selectFilter(c condition) filter {
+	return func(v interface{}) ([]interface{}, error) {
+		ok, err := c(v)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []interface{}{v}, nil
+	}
+}
+
+// operand returns the value of one side of a comparison, the first output
+// of f or null
+func operand(f filter, v interface{}) (interface{}, error) {
+	outs, err := f(v)
+	if err != nil || len(outs) == 0 {
+		return nil, err
+	}
+	return outs[0], nil
+}
+
+// compare returns a condition comparing the outputs of two filters
+func compare(left filter, op string, right filter) condition {
+	return func(v interface{}) (bool, error) {
+		a, err := operand(left, v)
+		if err != nil {
+			return false, err
+		}
+		b, err := operand(right, v)
+		if err != nil {
+			return false, err
+		}
+
+		switch op {
+		case "==":
+			return equal(a, b), nil
+		case "!=":
+			return !equal(a, b), nil
+		}
+
+		c, ok := order(a, b)
+		if !ok {
+			return false, nil
+		}
+		switch op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	}
+}
+
+// truthy returns a condition that holds when f gives neither false nor null
+func truthy(f filter) condition {
+	return func(v interface{}) (bool, error) {
+		out, err := operand(f, v)
+		if err != nil {
+			return false, err
+		}
+		return out != nil && out != false, nil
+	}
+}
+
+// equal compares two values, treating numbers by value
+func equal(a, b interface{}) bool {
+	x, aNum := number(a)
+	y, bNum := number(b)
+	if aNum || bNum {
+		return aNum && bNum && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// order compares two numbers or two strings
+func order(a, b interface{}) (int, bool) {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	s, ok := a.(string)
+	t, ok2 := b.(string)
+	if !ok || !ok2 {
+		return 0, false
+	}
+	switch {
+	case s < t:
+		return -1, true
+	case s > t:
+		return 1, true
+	}
+	return 0, true
+}
+
+// number returns v as a float64 if it is a number
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]interface{}) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+// typeName names the JSON type of v for errors
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decode decodes JSON the way queries receive it
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+const doc = `{
+	"results": [
+		{"title": "Go", "slug": "Go", "viewCount": 500, "tags": ["lang"]},
+		{"title": "Rust", "slug": "Rust", "viewCount": 90},
+		{"title": "Python", "slug": "Python", "viewCount": 1200}
+	],
+	"totalCount": 3,
+	"page": {"stats": {"totalViews": 7}}
+}`
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`.`, `[` + doc + `]`},
+		{`.totalCount`, `[3]`},
+		{`.page.stats.totalViews`, `[7]`},
+		{`.["totalCount"]`, `[3]`},
+		{`.missing`, `[null]`},
+		{`.missing.deeper`, `[null]`},
+		{`.results[0].slug`, `["Go"]`},
+		{`.results[-1].slug`, `["Python"]`},
+		{`.results[7]`, `[null]`},
+		{`.results[].slug`, `["Go", "Rust", "Python"]`},
+		{`.results[] | .title`, `["Go", "Rust", "Python"]`},
+		{`.results[] | select(.viewCount > 100) | .slug`, `["Go", "Python"]`},
+		{`.results[] | select(.viewCount >= 90 and .viewCount < 1000) | .slug`, `["Go", "Rust"]`},
+		{`.results[] | select(.slug == "Rust" or .slug == "Go") | .viewCount`, `[500, 90]`},
+		{`.results[] | select(.slug != "Go") | .slug`, `["Rust", "Python"]`},
+		{`.results[] | select(.tags) | .slug`, `["Go"]`},
+		{`.results[] | select(.title < "P") | .slug`, `["Go"]`},
+		{`.results | length`, `[3]`},
+		{`.page | keys`, `[["stats"]]`},
+		{`.page[]`, `[{"totalViews": 7}]`},
+	}
+
+	v := decode(t, doc)
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, err := q.Run(v)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got == nil {
+				got = []interface{}{}
+			}
+			want := decode(t, tt.want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Run() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, query := range []string{
+		``,
+		`results`,
+		`.results[`,
+		`.results[x]`,
+		`.a.`,
+		`.a b`,
+		`select(.a == )`,
+		`.a = 1`,
+		`"unterminated`,
+		`frobnicate`,
+	} {
+		if _, err := Parse(query); err == nil {
+			t.Errorf("Parse(%q) expected error", query)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	v := decode(t, doc)
+	for _, query := range []string{`.totalCount[]`, `.results.title`, `.totalCount[0]`, `.results | keys`} {
+		q, err := Parse(query)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", query, err)
+		}
+		if _, err := q.Run(v); err == nil {
+			t.Errorf("Run(%q) expected error", query)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grokipedia/cli/internal/api"
+)
+
+// SlugSuggestions returns up to limit slugs of pages whose titles start
+// with prefix, for completing page arguments. Underscores in prefix are
+// read as spaces, so a partially typed slug matches too.
+func (s *Service) SlugSuggestions(ctx context.Context, prefix string, limit int) ([]string, error) {
+	query := strings.ReplaceAll(prefix, "_", " ")
+	results, err := s.client.TypeaheadContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make([]string, 0, len(results.Suggestions))
+	for _, title := range results.Suggestions {
+		slugs = append(slugs, TitleSlug(title))
+	}
+	return slugs, nil
+}
+
+// EditStatuses returns the edit request statuses listed in the API
+// constants, or api.DefaultEditStatuses if the constants list none
+func (s *Service) EditStatuses(ctx context.Context) ([]string, error) {
+	constants, err := s.client.ConstantsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if statuses := constants.EditStatuses(); len(statuses) > 0 {
+		return statuses, nil
+	}
+	return api.DefaultEditStatuses, nil
+}
+
+// TitleSlug returns the slug of the page with the given title
+func TitleSlug(title string) string {
+	return strings.ReplaceAll(strings.TrimSpace(title), " ", "_")
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/template"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/render"
+	"github.com/grokipedia/cli/internal/formatter"
+	"github.com/grokipedia/cli/internal/query"
+)
+
+// fieldFormats are the formats whose columns can be chosen with --fields
+var fieldFormats = []formatter.FormatType{formatter.FormatTable, formatter.FormatCSV, formatter.FormatTSV}
+
+// Write writes an API response to w in one of formatter.TabularFormats.
+// API responses are written through their render counterparts; other
+// values must implement formatter.Renderable.
+func Write(w io.Writer, v interface{}, format string, opts formatter.Options) error {
+	if !slices.Contains(formatter.TabularFormats, format) {
+		return invalidFormat(format)
+	}
+	if len(opts.Fields) > 0 && !slices.Contains(fieldFormats, formatter.FormatType(format)) {
+		return &api.InvalidArgsError{Message: "--fields applies to table, csv and tsv output"}
+	}
+	return formatter.NewFormatterWithOptions(formatter.FormatType(format), opts).Format(render.Of(v), w)
+}
+
+// WritePage writes a page to w in one of formatter.Formats. The page
+// content is only written when showContent is set.
+func WritePage(w io.Writer, result *api.PageResponse, format string, showContent bool, opts formatter.Options) error {
+	if !slices.Contains(formatter.Formats, format) {
+		return invalidFormat(format)
+	}
+	if !showContent && !structured(format) {
+		page := *result
+		page.Page.Content = ""
+		result = &page
+	}
+	return Write(w, result, format, opts)
+}
+
+// WriteEdits writes edit requests to w in the given format. The text
+// formats end with the total count when showCounts is set.
+func WriteEdits(w io.Writer, results *api.EditsResponse, format string, showCounts bool, opts formatter.Options) error {
+	if !showCounts && !structured(format) {
+		edits := *results
+		edits.TotalCount = 0
+		results = &edits
+	}
+	return Write(w, results, format, opts)
+}
+
+// WriteConstants writes constants to w in the given format. A non-empty key
+// writes only that constant, and is an api.UnknownConstantError if missing.
+func WriteConstants(w io.Writer, results api.ConstantsResponse, key string, format string, opts formatter.Options) error {
+	if key != "" {
+		value, ok := results[key]
+		if !ok {
+			return &api.UnknownConstantError{Key: key}
+		}
+		results = api.ConstantsResponse{key: value}
+	}
+	return Write(w, results, format, opts)
+}
+
+// ParseTemplate parses text for the template format. Besides
+// formatter.TemplateFuncs, templates can call status to shorten an edit
+// request status. A malformed template is an api.InvalidArgsError.
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := formatter.ParseTemplate(text, template.FuncMap{"status": api.TrimEditStatus})
+	if err != nil {
+		return nil, &api.InvalidArgsError{Message: fmt.Sprintf("invalid template: %v", err)}
+	}
+	return tmpl, nil
+}
+
+// ParseQuery parses a --query expression. A malformed query is an
+// api.InvalidArgsError.
+func ParseQuery(expr string) (*query.Query, error) {
+	q, err := query.Parse(expr)
+	if err != nil {
+		return nil, &api.InvalidArgsError{Message: fmt.Sprintf("invalid query: %v", err)}
+	}
+	return q, nil
+}
+
+// ParseFields splits a comma-separated --fields value
+func ParseFields(fields string) []string {
+	return splitList(fields)
+}
+
+// structured reports whether format writes responses field for field, as
+// opposed to the text formats that choose what to show. Templates choose
+// for themselves, so they get the whole response.
+func structured(format string) bool {
+	switch formatter.FormatType(format) {
+	case formatter.FormatJSON, formatter.FormatNDJSON, formatter.FormatYAML, formatter.FormatTemplate:
+		return true
+	}
+	return false
+}
+
+// invalidFormat reports an output format the command does not support
+func invalidFormat(format string) error {
+	return &api.InvalidArgsError{Message: fmt.Sprintf("invalid format '%s'", format)}
+}
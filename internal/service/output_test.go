@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/formatter"
+)
+
+func TestWriteInvalidFormat(t *testing.T) {
+	var buf bytes.Buffer
+	errs := map[string]error{
+		"write":     Write(&buf, &api.SearchResponse{}, "xml", formatter.Options{}),
+		"page":      WritePage(&buf, &api.PageResponse{}, "xml", false, formatter.Options{}),
+		"page csv":  WritePage(&buf, &api.PageResponse{}, "csv", false, formatter.Options{}),
+		"edits":     WriteEdits(&buf, &api.EditsResponse{}, "xml", false, formatter.Options{}),
+		"constants": WriteConstants(&buf, api.ConstantsResponse{}, "", "xml", formatter.Options{}),
+	}
+	for name, err := range errs {
+		if api.GetExitCode(err) != api.ExitInvalidArgs {
+			t.Errorf("%s: expected invalid args error, got %v", name, err)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output for invalid formats, got %q", buf.String())
+	}
+}
+
+func TestWriteEveryFormat(t *testing.T) {
+	responses := map[string]interface{}{
+		"search":        &api.SearchResponse{Results: []api.SearchResult{{Title: "Go", Slug: "Go"}}},
+		"page":          &api.PageResponse{Found: true, Page: api.PageData{Title: "Go", Slug: "Go"}},
+		"edits":         &api.EditsResponse{EditRequests: []api.EditRequest{{ID: "req-1", Slug: "Go"}}},
+		"edits-by-slug": &api.EditsBySlugResponse{EditRequests: []api.EditRequest{{ID: "req-1"}}},
+		"typeahead":     &api.TypeaheadResponse{Suggestions: []string{"Go"}},
+		"constants":     api.ConstantsResponse{"Go": 1},
+	}
+
+	tmpl, err := ParseTemplate("{{json .}}")
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+
+	for name, response := range responses {
+		for _, format := range formatter.TabularFormats {
+			var buf bytes.Buffer
+			if err := Write(&buf, response, format, formatter.Options{Template: tmpl}); err != nil {
+				t.Errorf("%s as %s: Write() error = %v", name, format, err)
+				continue
+			}
+			if !strings.Contains(buf.String(), "Go") && !strings.Contains(buf.String(), "req-1") {
+				t.Errorf("%s as %s: unexpected output %q", name, format, buf.String())
+			}
+		}
+	}
+}
+
+func TestWritePageContent(t *testing.T) {
+	result := &api.PageResponse{Found: true, Page: api.PageData{Title: "Go", Slug: "Go", Content: "Go is a language."}}
+
+	var buf bytes.Buffer
+	if err := WritePage(&buf, result, "markdown", false, formatter.Options{}); err != nil {
+		t.Fatalf("WritePage() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "Go is a language.") {
+		t.Error("Expected content to be omitted without showContent")
+	}
+
+	buf.Reset()
+	if err := WritePage(&buf, result, "plain", true, formatter.Options{}); err != nil {
+		t.Fatalf("WritePage() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Content:\nGo is a language.") {
+		t.Errorf("Expected content in plain output, got %q", buf.String())
+	}
+}
+
+func TestWriteEditsTable(t *testing.T) {
+	results := &api.EditsResponse{
+		EditRequests: []api.EditRequest{{ID: "req-1", Slug: "Go", Status: "EDIT_REQUEST_STATUS_APPROVED", Editor: "alice"}},
+		TotalCount:   3,
+		HasMore:      true,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEdits(&buf, results, "table", true, formatter.Options{}); err != nil {
+		t.Fatalf("WriteEdits() error = %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "APPROVED") || strings.Contains(out, "EDIT_REQUEST_STATUS_") {
+		t.Errorf("Expected shortened status, got %q", out)
+	}
+	if !strings.Contains(out, "Total: 3 (more available)") {
+		t.Errorf("Expected total line, got %q", out)
+	}
+
+	buf.Reset()
+	if err := WriteEdits(&buf, results, "table", false, formatter.Options{}); err != nil {
+		t.Fatalf("WriteEdits() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "Total:") {
+		t.Errorf("Expected no total line without showCounts, got %q", buf.String())
+	}
+}
+
+func TestWriteConstantsKey(t *testing.T) {
+	constants := api.ConstantsResponse{"wanted": "yes", "other": "no"}
+
+	var buf bytes.Buffer
+	if err := WriteConstants(&buf, constants, "wanted", "yaml", formatter.Options{}); err != nil {
+		t.Fatalf("WriteConstants() error = %v", err)
+	}
+	if buf.String() != "wanted: \"yes\"\n" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+
+	err := WriteConstants(&buf, constants, "missing", "json", formatter.Options{})
+	if api.GetExitCode(err) != api.ExitNotFound {
+		t.Errorf("Expected unknown constant error, got %v", err)
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{range .EditRequests}}{{.ID}} {{status .Status}} {{date .Timestamp "2006-01-02"}}{{"\n"}}{{end}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	results := &api.EditsResponse{EditRequests: []api.EditRequest{
+		{ID: "req-1", Status: "EDIT_REQUEST_STATUS_APPROVED", Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local).Unix()},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteEdits(&buf, results, "template", false, formatter.Options{Template: tmpl}); err != nil {
+		t.Fatalf("WriteEdits() error = %v", err)
+	}
+	if buf.String() != "req-1 APPROVED 2024-03-01\n" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+
+	if _, err := ParseTemplate("{{.Title"); api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Expected invalid args error for a malformed template, got %v", err)
+	}
+}
+
+func TestWriteFieldsAndQuery(t *testing.T) {
+	result := &api.PageResponse{Found: true, Page: api.PageData{Title: "Go", Slug: "Go", Stats: api.PageStats{TotalViews: 42}}}
+	opts := formatter.Options{Fields: ParseFields("title, stats.totalViews")}
+
+	var buf bytes.Buffer
+	if err := WritePage(&buf, result, "table", false, opts); err != nil {
+		t.Fatalf("WritePage() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "stats.totalViews") || !strings.Contains(buf.String(), "42") {
+		t.Errorf("Expected page fields in table, got %q", buf.String())
+	}
+
+	err := WritePage(&buf, result, "json", false, opts)
+	if api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Expected invalid args error for --fields with json, got %v", err)
+	}
+
+	q, err := ParseQuery(".page.stats.totalViews")
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+	buf.Reset()
+	if err := WritePage(&buf, result, "json", false, formatter.Options{Query: q}); err != nil {
+		t.Fatalf("WritePage() error = %v", err)
+	}
+	if buf.String() != "42\n" {
+		t.Errorf("Expected queried value, got %q", buf.String())
+	}
+
+	if _, err := ParseQuery(".page["); api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Expected invalid args error for a malformed query, got %v", err)
+	}
+}
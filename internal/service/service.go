@@ -0,0 +1,245 @@
+// Package service implements what each CLI command does: it fetches from the
+// API through the cache and writes the result in the requested format. The
+// command layer only maps flags onto these calls.
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/cache"
+)
+
+// Service runs commands against a cached client
+type Service struct {
+	client *cache.CachedClient
+}
+
+// New returns a Service fetching from client through c, which may be nil
+// to disable caching
+func New(client api.GrokipediaAPI, c *cache.Cache) *Service {
+	return &Service{client: cache.NewCachedClient(client, c)}
+}
+
+// SearchOptions configures Search
+type SearchOptions struct {
+	Query  string
+	Limit  int
+	Offset int
+	// All fetches every page of results, using Limit as the page size
+	All bool
+	// Max stops an All search after this many results; zero means no limit
+	Max int
+}
+
+// Search runs a full-text search
+func (s *Service) Search(ctx context.Context, opts SearchOptions) (*api.SearchResponse, error) {
+	if err := checkMax(opts.All, opts.Max); err != nil {
+		return nil, err
+	}
+
+	if !opts.All {
+		return s.client.SearchContext(ctx, opts.Query, opts.Limit, opts.Offset)
+	}
+
+	req := cache.Request{Endpoint: api.EndpointSearch, Params: map[string]interface{}{
+		"q":      opts.Query,
+		"limit":  opts.Limit,
+		"offset": opts.Offset,
+		"all":    true,
+		"max":    opts.Max,
+	}}
+	return cache.Do(ctx, s.client, req, func(ctx context.Context, client api.GrokipediaAPI) (*api.SearchResponse, error) {
+		first := &firstPages{GrokipediaAPI: client}
+		results, err := api.Collect(api.SearchAll(ctx, first, opts.Query, api.PageOptions{
+			PageSize: opts.Limit,
+			Offset:   opts.Offset,
+			MaxItems: opts.Max,
+		}))
+		if err != nil {
+			return nil, err
+		}
+
+		// Totals, facets and timings are those of the first page
+		response := &api.SearchResponse{}
+		if first.search != nil {
+			*response = *first.search
+		}
+		response.Results = results
+		return response, nil
+	})
+}
+
+// Page returns the page for slug. A missing page is an api.NotFoundError.
+func (s *Service) Page(ctx context.Context, slug string, includeContent, validateLinks bool) (*api.PageResponse, error) {
+	return s.client.PageContext(ctx, slug, includeContent, validateLinks)
+}
+
+// EditsOptions configures Edits
+type EditsOptions struct {
+	Limit int
+	// Status filters by comma-separated statuses
+	Status string
+	// ExcludeUsers drops edit requests by these editors
+	ExcludeUsers  []string
+	IncludeCounts bool
+	// All fetches every page of edit requests, using Limit as the page size
+	All bool
+	// Max stops an All listing after this many edit requests; zero means no
+	// limit
+	Max int
+}
+
+// Edits lists edit requests
+func (s *Service) Edits(ctx context.Context, opts EditsOptions) (*api.EditsResponse, error) {
+	if err := checkMax(opts.All, opts.Max); err != nil {
+		return nil, err
+	}
+
+	statuses := splitList(opts.Status)
+	if !opts.All {
+		return s.client.EditsContext(ctx, opts.Limit, statuses, opts.ExcludeUsers, opts.IncludeCounts)
+	}
+
+	params := cache.EditsParams(opts.Limit, 0, statuses, opts.ExcludeUsers, opts.IncludeCounts)
+	params["all"] = true
+	params["max"] = opts.Max
+
+	req := cache.Request{Endpoint: api.EndpointEdits, Params: params}
+	return cache.Do(ctx, s.client, req, func(ctx context.Context, client api.GrokipediaAPI) (*api.EditsResponse, error) {
+		first := &firstPages{GrokipediaAPI: client}
+		edits, err := api.Collect(api.EditsAll(ctx, first, statuses, opts.ExcludeUsers, api.PageOptions{
+			PageSize: opts.Limit,
+			MaxItems: opts.Max,
+		}))
+		if err != nil {
+			return nil, err
+		}
+
+		response := &api.EditsResponse{EditRequests: edits}
+		if first.edits != nil {
+			response.TotalCount = first.edits.TotalCount
+			response.TotalCountUnfiltered = first.edits.TotalCountUnfiltered
+			response.HasMore = len(edits) < first.edits.TotalCount
+		}
+		return response, nil
+	})
+}
+
+// EditsBySlugOptions configures EditsBySlug
+type EditsBySlugOptions struct {
+	Slug   string
+	Limit  int
+	Offset int
+	// All fetches every page of edit requests, using Limit as the page size
+	All bool
+	// Max stops an All listing after this many edit requests; zero means no
+	// limit
+	Max int
+}
+
+// EditsBySlug lists the edit requests for a page
+func (s *Service) EditsBySlug(ctx context.Context, opts EditsBySlugOptions) (*api.EditsBySlugResponse, error) {
+	if err := checkMax(opts.All, opts.Max); err != nil {
+		return nil, err
+	}
+
+	if !opts.All {
+		return s.client.EditsBySlugContext(ctx, opts.Slug, opts.Limit, opts.Offset)
+	}
+
+	req := cache.Request{Endpoint: api.EndpointEditsBySlug, Params: map[string]interface{}{
+		"slug":   opts.Slug,
+		"limit":  opts.Limit,
+		"offset": opts.Offset,
+		"all":    true,
+		"max":    opts.Max,
+	}}
+	return cache.Do(ctx, s.client, req, func(ctx context.Context, client api.GrokipediaAPI) (*api.EditsBySlugResponse, error) {
+		first := &firstPages{GrokipediaAPI: client}
+		edits, err := api.Collect(api.EditsBySlugAll(ctx, first, opts.Slug, api.PageOptions{
+			PageSize: opts.Limit,
+			Offset:   opts.Offset,
+			MaxItems: opts.Max,
+		}))
+		if err != nil {
+			return nil, err
+		}
+
+		response := &api.EditsBySlugResponse{EditRequests: edits}
+		if first.editsBySlug != nil {
+			response.TotalCount = first.editsBySlug.TotalCount
+			response.TotalCountUnfiltered = first.editsBySlug.TotalCountUnfiltered
+			response.HasMore = opts.Offset+len(edits) < first.editsBySlug.TotalCount
+		}
+		return response, nil
+	})
+}
+
+// Typeahead returns up to limit title suggestions for query
+func (s *Service) Typeahead(ctx context.Context, query string, limit int) (*api.TypeaheadResponse, error) {
+	return s.client.TypeaheadContext(ctx, query, limit)
+}
+
+// Constants returns the API constants
+func (s *Service) Constants(ctx context.Context) (api.ConstantsResponse, error) {
+	return s.client.ConstantsContext(ctx)
+}
+
+// firstPages wraps a client to keep the first page of each listing it
+// fetches, so that the counts reported by the server can be returned with
+// the items collected from every page
+type firstPages struct {
+	api.GrokipediaAPI
+	search      *api.SearchResponse
+	edits       *api.EditsResponse
+	editsBySlug *api.EditsBySlugResponse
+}
+
+// SearchContext implements api.GrokipediaAPI
+func (f *firstPages) SearchContext(ctx context.Context, query string, limit, offset int) (*api.SearchResponse, error) {
+	resp, err := f.GrokipediaAPI.SearchContext(ctx, query, limit, offset)
+	if err == nil && f.search == nil {
+		f.search = resp
+	}
+	return resp, err
+}
+
+// EditsPageContext implements api.GrokipediaAPI
+func (f *firstPages) EditsPageContext(ctx context.Context, limit, offset int, status []string, excludeUsers []string, includeCounts bool) (*api.EditsResponse, error) {
+	resp, err := f.GrokipediaAPI.EditsPageContext(ctx, limit, offset, status, excludeUsers, includeCounts)
+	if err == nil && f.edits == nil {
+		f.edits = resp
+	}
+	return resp, err
+}
+
+// EditsBySlugContext implements api.GrokipediaAPI
+func (f *firstPages) EditsBySlugContext(ctx context.Context, slug string, limit, offset int) (*api.EditsBySlugResponse, error) {
+	resp, err := f.GrokipediaAPI.EditsBySlugContext(ctx, slug, limit, offset)
+	if err == nil && f.editsBySlug == nil {
+		f.editsBySlug = resp
+	}
+	return resp, err
+}
+
+// checkMax rejects a --max limit without --all
+func checkMax(all bool, max int) error {
+	if max > 0 && !all {
+		return &api.InvalidArgsError{Message: "--max requires --all"}
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, trimming spaces
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	items := strings.Split(s, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
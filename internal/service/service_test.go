@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/apitest"
+	"github.com/grokipedia/cli/internal/cache"
+)
+
+// newFixtureFake creates a fake API client seeded from the repository
+// fixtures
+func newFixtureFake(t *testing.T) *apitest.Fake {
+	t.Helper()
+
+	f, err := apitest.NewFromFixtures("../../testdata/fixtures")
+	if err != nil {
+		t.Fatalf("failed to load API fixtures: %v", err)
+	}
+	return f
+}
+
+func TestSearchCaches(t *testing.T) {
+	fake := newFixtureFake(t)
+	svc := New(fake, cache.New(t.TempDir(), 3600))
+
+	for i := 0; i < 2; i++ {
+		results, err := svc.Search(context.Background(), SearchOptions{Query: "python", Limit: 12})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(results.Results) == 0 {
+			t.Fatal("Expected fixture results")
+		}
+	}
+	if got := fake.Calls(apitest.MethodSearch); got != 1 {
+		t.Errorf("Expected the second search to be served from the cache, got %d calls", got)
+	}
+}
+
+func TestSearchAll(t *testing.T) {
+	var all []api.SearchResult
+	for i := 0; i < 25; i++ {
+		all = append(all, api.SearchResult{Slug: fmt.Sprintf("Page_%d", i)})
+	}
+	fake := apitest.New()
+	fake.SearchFunc = func(ctx context.Context, query string, limit, offset int) (*api.SearchResponse, error) {
+		return &api.SearchResponse{
+			Results:          all[offset:min(offset+limit, len(all))],
+			TotalCount:       len(all),
+			Facets:           []interface{}{"type"},
+			SearchTimeMs:     float64(offset + 1),
+			DetectedLanguage: "en",
+		}, nil
+	}
+	svc := New(fake, nil)
+
+	results, err := svc.Search(context.Background(), SearchOptions{Query: "page", Limit: 8, All: true, Max: 20})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results.Results) != 20 {
+		t.Errorf("Expected 20 results capped by Max, got %d", len(results.Results))
+	}
+	if results.TotalCount != 25 {
+		t.Errorf("Expected the total reported by the server, got %d", results.TotalCount)
+	}
+	if len(results.Facets) != 1 || results.SearchTimeMs != 1 || results.DetectedLanguage != "en" {
+		t.Errorf("Expected the facets, timing and language of the first page, got %v, %v, %q",
+			results.Facets, results.SearchTimeMs, results.DetectedLanguage)
+	}
+	if got := fake.Calls(apitest.MethodSearch); got != 3 {
+		t.Errorf("Expected 3 page requests, got %d", got)
+	}
+}
+
+func TestEditsAllKeepsTotal(t *testing.T) {
+	fake := apitest.New()
+	for i := 0; i < 5; i++ {
+		fake.EditRequests = append(fake.EditRequests, api.EditRequest{ID: fmt.Sprintf("req-%d", i)})
+	}
+	svc := New(fake, nil)
+
+	results, err := svc.Edits(context.Background(), EditsOptions{Limit: 2, All: true, Max: 3})
+	if err != nil {
+		t.Fatalf("Edits() error = %v", err)
+	}
+	if len(results.EditRequests) != 3 {
+		t.Errorf("Expected 3 edit requests capped by Max, got %d", len(results.EditRequests))
+	}
+	if results.TotalCount != 5 || !results.HasMore {
+		t.Errorf("Expected total 5 with more available, got %d (more %v)", results.TotalCount, results.HasMore)
+	}
+}
+
+func TestMaxRequiresAll(t *testing.T) {
+	svc := New(apitest.New(), nil)
+	ctx := context.Background()
+
+	_, err := svc.Search(ctx, SearchOptions{Query: "go", Max: 5})
+	if api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Search() expected invalid args error, got %v", err)
+	}
+	_, err = svc.Edits(ctx, EditsOptions{Max: 5})
+	if api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("Edits() expected invalid args error, got %v", err)
+	}
+	_, err = svc.EditsBySlug(ctx, EditsBySlugOptions{Slug: "Go", Max: 5})
+	if api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("EditsBySlug() expected invalid args error, got %v", err)
+	}
+}
+
+func TestEditsStatusFilter(t *testing.T) {
+	fake := apitest.New()
+	var gotStatus []string
+	fake.EditsFunc = func(ctx context.Context, limit, offset int, status []string, excludeUsers []string, includeCounts bool) (*api.EditsResponse, error) {
+		gotStatus = status
+		return &api.EditsResponse{}, nil
+	}
+	svc := New(fake, nil)
+
+	if _, err := svc.Edits(context.Background(), EditsOptions{Limit: 20, Status: "approved, pending"}); err != nil {
+		t.Fatalf("Edits() error = %v", err)
+	}
+	if len(gotStatus) != 2 || gotStatus[0] != "approved" || gotStatus[1] != "pending" {
+		t.Errorf("Expected trimmed statuses [approved pending], got %q", gotStatus)
+	}
+}
+
+func TestPageNotFound(t *testing.T) {
+	c := cache.New(t.TempDir(), 3600)
+	svc := New(newFixtureFake(t), c)
+
+	_, err := svc.Page(context.Background(), "Missing_page", false, true)
+	if api.GetExitCode(err) != api.ExitNotFound {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if infos, _ := c.Entries(); len(infos) != 0 {
+		t.Errorf("Expected missing page not to be cached, got %d entries", len(infos))
+	}
+}
+
+func TestTypeaheadAndConstants(t *testing.T) {
+	svc := New(newFixtureFake(t), cache.New(t.TempDir(), 3600))
+	ctx := context.Background()
+
+	suggestions, err := svc.Typeahead(ctx, "python s", 5)
+	if err != nil {
+		t.Fatalf("Typeahead() error = %v", err)
+	}
+	if len(suggestions.Suggestions) == 0 {
+		t.Error("Expected fixture suggestions")
+	}
+
+	constants, err := svc.Constants(ctx)
+	if err != nil {
+		t.Fatalf("Constants() error = %v", err)
+	}
+	if len(constants) == 0 {
+		t.Error("Expected fixture constants")
+	}
+}
+
+func TestSlugSuggestions(t *testing.T) {
+	svc := New(newFixtureFake(t), nil)
+
+	slugs, err := svc.SlugSuggestions(context.Background(), "Python_s", 10)
+	if err != nil {
+		t.Fatalf("SlugSuggestions() error = %v", err)
+	}
+	if len(slugs) != 2 || slugs[0] != "Python_syntax" || slugs[1] != "Python_standard_library" {
+		t.Errorf("SlugSuggestions() = %v, want [Python_syntax Python_standard_library]", slugs)
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"iter"
+
+	"github.com/grokipedia/cli/internal/api"
+)
+
+// SearchStream returns an iterator over the results of a search. With All
+// set, pages are fetched through the cache one at a time as the iterator
+// is drained, so that results can be written as they arrive with constant
+// memory.
+func (s *Service) SearchStream(ctx context.Context, opts SearchOptions) iter.Seq2[api.SearchResult, error] {
+	if err := checkMax(opts.All, opts.Max); err != nil {
+		return failed[api.SearchResult](err)
+	}
+
+	if opts.All {
+		return api.SearchAll(ctx, s.client, opts.Query, api.PageOptions{
+			PageSize: opts.Limit,
+			Offset:   opts.Offset,
+			MaxItems: opts.Max,
+		})
+	}
+	return single(func() ([]api.SearchResult, error) {
+		results, err := s.client.SearchContext(ctx, opts.Query, opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		return results.Results, nil
+	})
+}
+
+// EditsStream returns an iterator over edit requests, fetching pages one at
+// a time when All is set; see SearchStream
+func (s *Service) EditsStream(ctx context.Context, opts EditsOptions) iter.Seq2[api.EditRequest, error] {
+	if err := checkMax(opts.All, opts.Max); err != nil {
+		return failed[api.EditRequest](err)
+	}
+
+	statuses := splitList(opts.Status)
+	if opts.All {
+		return api.EditsAll(ctx, s.client, statuses, opts.ExcludeUsers, api.PageOptions{
+			PageSize: opts.Limit,
+			MaxItems: opts.Max,
+		})
+	}
+	return single(func() ([]api.EditRequest, error) {
+		results, err := s.client.EditsContext(ctx, opts.Limit, statuses, opts.ExcludeUsers, opts.IncludeCounts)
+		if err != nil {
+			return nil, err
+		}
+		return results.EditRequests, nil
+	})
+}
+
+// EditsBySlugStream returns an iterator over the edit requests for a page,
+// fetching pages one at a time when All is set; see SearchStream
+func (s *Service) EditsBySlugStream(ctx context.Context, opts EditsBySlugOptions) iter.Seq2[api.EditRequest, error] {
+	if err := checkMax(opts.All, opts.Max); err != nil {
+		return failed[api.EditRequest](err)
+	}
+
+	if opts.All {
+		return api.EditsBySlugAll(ctx, s.client, opts.Slug, api.PageOptions{
+			PageSize: opts.Limit,
+			Offset:   opts.Offset,
+			MaxItems: opts.Max,
+		})
+	}
+	return single(func() ([]api.EditRequest, error) {
+		results, err := s.client.EditsBySlugContext(ctx, opts.Slug, opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		return results.EditRequests, nil
+	})
+}
+
+// single returns an iterator over the items of one fetch
+func single[T any](fetch func() ([]T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		items, err := fetch()
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// failed returns an iterator yielding only err
+func failed[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/grokipedia/cli/internal/api"
+	"github.com/grokipedia/cli/internal/api/apitest"
+	"github.com/grokipedia/cli/internal/cache"
+)
+
+func TestSearchStreamFetchesLazily(t *testing.T) {
+	fake := apitest.New()
+	for i := 0; i < 25; i++ {
+		fake.SearchResults = append(fake.SearchResults, api.SearchResult{Slug: fmt.Sprintf("Page_%d", i)})
+	}
+	svc := New(fake, cache.New(t.TempDir(), 3600))
+	opts := SearchOptions{Query: "page", Limit: 10, All: true}
+
+	for result, err := range svc.SearchStream(context.Background(), opts) {
+		if err != nil {
+			t.Fatalf("SearchStream() error = %v", err)
+		}
+		if result.Slug != "Page_0" {
+			t.Errorf("Expected Page_0 first, got %s", result.Slug)
+		}
+		break
+	}
+	if got := fake.Calls(apitest.MethodSearch); got != 1 {
+		t.Errorf("Expected only the first page to be fetched, got %d calls", got)
+	}
+
+	results, err := api.Collect(svc.SearchStream(context.Background(), opts))
+	if err != nil {
+		t.Fatalf("SearchStream() error = %v", err)
+	}
+	if len(results) != 25 {
+		t.Errorf("Expected 25 results, got %d", len(results))
+	}
+	// The first page comes from the cache
+	if got := fake.Calls(apitest.MethodSearch); got != 3 {
+		t.Errorf("Expected 3 page requests in all, got %d", got)
+	}
+}
+
+func TestEditsStreamSinglePage(t *testing.T) {
+	fake := apitest.New()
+	for i := 0; i < 5; i++ {
+		fake.EditRequests = append(fake.EditRequests, api.EditRequest{ID: fmt.Sprintf("req-%d", i), Slug: "Go"})
+	}
+	svc := New(fake, nil)
+
+	edits, err := api.Collect(svc.EditsStream(context.Background(), EditsOptions{Limit: 3}))
+	if err != nil {
+		t.Fatalf("EditsStream() error = %v", err)
+	}
+	if len(edits) != 3 {
+		t.Errorf("Expected one page of 3 edit requests, got %d", len(edits))
+	}
+
+	bySlug, err := api.Collect(svc.EditsBySlugStream(context.Background(), EditsBySlugOptions{Slug: "Go", Limit: 2, All: true}))
+	if err != nil {
+		t.Fatalf("EditsBySlugStream() error = %v", err)
+	}
+	if len(bySlug) != 5 {
+		t.Errorf("Expected every edit request for the page, got %d", len(bySlug))
+	}
+}
+
+func TestStreamMaxRequiresAll(t *testing.T) {
+	svc := New(apitest.New(), nil)
+
+	_, err := api.Collect(svc.SearchStream(context.Background(), SearchOptions{Query: "go", Max: 5}))
+	if api.GetExitCode(err) != api.ExitInvalidArgs {
+		t.Errorf("SearchStream() expected invalid args error, got %v", err)
+	}
+}